@@ -21,9 +21,19 @@ func CodeGenFunc(ctx context.Context, parser *gcmd.Parser) error {
 	yamlInputPath := parser.GetOpt("yamlInputPath", "manifest/config/codegen_conf").String()
 	serviceOnly := parser.GetOpt("serviceOnly").Bool()
 	smartCache := parser.GetOpt("smartCache").Bool()
-	frontendType := parser.GetOpt("frontendType").String()
+	cacheBackend := parser.GetOpt("cacheBackend", common.CacheBackendGfCache).String()
+	withTests := parser.GetOpt("withTests").Bool()
+	withMocks := parser.GetOpt("withMocks").Bool()
+	frontendType := parser.GetOpt("frontendType", common.FrontendTypeVue).String()
 	frontendPath := parser.GetOpt("frontendPath").String()
 
+	if cacheBackend != common.CacheBackendGfCache && cacheBackend != common.CacheBackendMemory {
+		return gerror.Newf("cacheBackend %s 不正确，只能为 gfcache 或 memory", cacheBackend)
+	}
+	if frontendType != common.FrontendTypeVue && frontendType != common.FrontendTypeArco {
+		return gerror.Newf("frontendType %s 不正确，只能为 vue 或 arco", frontendType)
+	}
+
 	tableNamesFilter := gset.NewStrSetFrom(common.SplitComma(tablesStr))
 	tablePrefixesOnly := common.SplitComma(tablePrefixOnlyStr)
 	goModuleName, err := common.GetGoModuleName()
@@ -36,6 +46,9 @@ func CodeGenFunc(ctx context.Context, parser *gcmd.Parser) error {
 		GoModuleName:  goModuleName,
 		ServiceOnly:   serviceOnly,
 		SmartCache:    smartCache,
+		CacheBackend:  cacheBackend,
+		WithTests:     withTests,
+		WithMocks:     withMocks,
 		FrontendType:  frontendType,
 		FrontendPath:  frontendPath,
 	}
@@ -102,12 +115,18 @@ func CodeGenFunc(ctx context.Context, parser *gcmd.Parser) error {
 			return err
 		}
 	}
-	if smartCache {
+	if smartCache && cacheBackend == common.CacheBackendGfCache {
 		err = internal.ImportModule(ctx, "github.com/WesleyWu/gf-cache")
 		if err != nil {
 			return err
 		}
 	}
+	if withTests {
+		err = internal.ImportModule(ctx, "github.com/gogf/gf/contrib/drivers/sqlite/v2")
+		if err != nil {
+			return err
+		}
+	}
 	g.Log().Info(ctx, "executing go mod tidy")
 	err = internal.ExecCommand(ctx, "go", "mod", "tidy")
 	if err != nil {
@@ -21,9 +21,24 @@ import (
 	"strings"
 )
 
+//go:embed template/go/audit.template
+var auditTemplate string
+
+//go:embed template/go/export.template
+var exportTemplate string
+
+//go:embed template/go/importer.template
+var importerTemplate string
+
+//go:embed template/go/cursor.template
+var cursorTemplate string
+
 //go:embed template/go/controller.template
 var controllerTemplate string
 
+//go:embed template/go/gateway.template
+var gatewayTemplate string
+
 //go:embed template/go/dao.template
 var daoTemplate string
 
@@ -45,9 +60,33 @@ var routerTemplate string
 //go:embed template/go/service.template
 var serviceTemplate string
 
+//go:embed template/go/service_test.template
+var serviceTestTemplate string
+
+//go:embed template/go/gateway_test.template
+var gatewayTestTemplate string
+
+//go:embed template/go/testdb.template
+var testdbTemplate string
+
+//go:embed template/go/service_mock.template
+var serviceMockTemplate string
+
+//go:embed template/go/service_grpc.template
+var serviceGrpcTemplate string
+
 //go:embed template/go/service.cache.proxy.template
 var serviceCacheProxyTemplate string
 
+//go:embed template/go/smartcache.template
+var smartcacheTemplate string
+
+//go:embed template/go/smartcache_memory.template
+var smartcacheMemoryTemplate string
+
+//go:embed template/go/smartcache_gfcache.template
+var smartcacheGfCacheTemplate string
+
 //go:embed template/js/api.template
 var jsapiTemplate string
 
@@ -57,12 +96,27 @@ var protobufTemplate string
 //go:embed template/sql/sql.template
 var sqlTemplate string
 
+//go:embed template/sql/history.template
+var historySqlTemplate string
+
 //go:embed template/vue/list-vue.template
 var listVueTemplate string
 
 //go:embed template/vue/tree-vue.template
 var treeVueTemplate string
 
+//go:embed template/ts/api.template
+var tsApiTemplate string
+
+//go:embed template/ts/request.template
+var tsRequestTemplate string
+
+//go:embed template/react/list-tsx.template
+var listReactTemplate string
+
+//go:embed template/react/tree-tsx.template
+var treeReactTemplate string
+
 func GenCodeByTableDefYaml(ctx context.Context, tableName string, genOptions *common.GenOptions) error {
 	var cache = map[string]*common.TableDef{}
 	table, err := common.LoadTableDefYaml(ctx, tableName, genOptions.YamlInputPath, genOptions.GoModuleName, cache)
@@ -70,21 +124,6 @@ func GenCodeByTableDefYaml(ctx context.Context, tableName string, genOptions *co
 		return err
 	}
 	if table.IsRpc {
-		// make sure protoc can work properly
-		protocVersionOk, err1 := protobuf.IsProtocVersionOK()
-		if err1 != nil {
-			return err
-		}
-		if !protocVersionOk {
-			return gerror.New("请安装3.0以上版本的 protoc")
-		}
-		protocTripleVersionOk, err2 := protobuf.IsProtocTripleVersionOK()
-		if err2 != nil {
-			return err
-		}
-		if !protocTripleVersionOk {
-			return gerror.New("请安装1.0以上版本的 protoc-gen-go-triple")
-		}
 		if g.IsEmpty(table.RpcPort) {
 			return gerror.New("必须指定rpc服务侦听端口 RpcPort，建议20000以上，各服务的端口号不能重复")
 		}
@@ -99,6 +138,16 @@ func GenCodeByTableDefYaml(ctx context.Context, tableName string, genOptions *co
 		g.Log().Error(ctx, err)
 		return err
 	}
+	err = table.ProcessChildren(ctx, genOptions.YamlInputPath, genOptions.GoModuleName, cache)
+	if err != nil {
+		g.Log().Error(ctx, err)
+		return err
+	}
+	err = table.ProcessManyToMany(ctx, genOptions.YamlInputPath, genOptions.GoModuleName, cache)
+	if err != nil {
+		g.Log().Error(ctx, err)
+		return err
+	}
 
 	err = doGenCode(ctx, table, genOptions)
 	if err != nil {
@@ -157,7 +206,12 @@ func prepareTemplateData(ctx context.Context, table *common.TableDef, genOptions
 	controllerKey := "controller"
 	controllerValue := ""
 	var tmpController string
-	if tmpController, err = view.ParseContent(ctx, controllerTemplate, tplData); err == nil {
+	// rpc 服务生成 http 网关时，由网关代替控制器处理 http 请求
+	controllerTpl := controllerTemplate
+	if table.IsRpc && table.RpcHttp {
+		controllerTpl = gatewayTemplate
+	}
+	if tmpController, err = view.ParseContent(ctx, controllerTpl, tplData); err == nil {
 		controllerValue = tmpController
 		controllerValue, err = common.TrimBreak(controllerValue)
 	} else {
@@ -184,6 +238,86 @@ func prepareTemplateData(ctx context.Context, table *common.TableDef, genOptions
 		return
 	}
 
+	smartcacheKey := "smartcache"
+	smartcacheValue := ""
+	var tmpSmartcache string
+	if tmpSmartcache, err = view.ParseContent(ctx, smartcacheTemplate, tplData); err == nil {
+		smartcacheValue = tmpSmartcache
+		smartcacheValue, err = common.TrimBreak(smartcacheValue)
+	} else {
+		return
+	}
+
+	smartcacheMemoryKey := "smartcacheMemory"
+	smartcacheMemoryValue := ""
+	var tmpSmartcacheMemory string
+	if tmpSmartcacheMemory, err = view.ParseContent(ctx, smartcacheMemoryTemplate, tplData); err == nil {
+		smartcacheMemoryValue = tmpSmartcacheMemory
+		smartcacheMemoryValue, err = common.TrimBreak(smartcacheMemoryValue)
+	} else {
+		return
+	}
+
+	smartcacheGfCacheKey := "smartcacheGfCache"
+	smartcacheGfCacheValue := ""
+	var tmpSmartcacheGfCache string
+	if tmpSmartcacheGfCache, err = view.ParseContent(ctx, smartcacheGfCacheTemplate, tplData); err == nil {
+		smartcacheGfCacheValue = tmpSmartcacheGfCache
+		smartcacheGfCacheValue, err = common.TrimBreak(smartcacheGfCacheValue)
+	} else {
+		return
+	}
+
+	serviceTestKey := "serviceTest"
+	serviceTestValue := ""
+	var tmpServiceTest string
+	if tmpServiceTest, err = view.ParseContent(ctx, serviceTestTemplate, tplData); err == nil {
+		serviceTestValue = tmpServiceTest
+		serviceTestValue, err = common.TrimBreak(serviceTestValue)
+	} else {
+		return
+	}
+
+	gatewayTestKey := "gatewayTest"
+	gatewayTestValue := ""
+	var tmpGatewayTest string
+	if tmpGatewayTest, err = view.ParseContent(ctx, gatewayTestTemplate, tplData); err == nil {
+		gatewayTestValue = tmpGatewayTest
+		gatewayTestValue, err = common.TrimBreak(gatewayTestValue)
+	} else {
+		return
+	}
+
+	testdbKey := "testdb"
+	testdbValue := ""
+	var tmpTestdb string
+	if tmpTestdb, err = view.ParseContent(ctx, testdbTemplate, tplData); err == nil {
+		testdbValue = tmpTestdb
+		testdbValue, err = common.TrimBreak(testdbValue)
+	} else {
+		return
+	}
+
+	serviceMockKey := "serviceMock"
+	serviceMockValue := ""
+	var tmpServiceMock string
+	if tmpServiceMock, err = view.ParseContent(ctx, serviceMockTemplate, tplData); err == nil {
+		serviceMockValue = tmpServiceMock
+		serviceMockValue, err = common.TrimBreak(serviceMockValue)
+	} else {
+		return
+	}
+
+	serviceGrpcKey := "serviceGrpc"
+	serviceGrpcValue := ""
+	var tmpServiceGrpc string
+	if tmpServiceGrpc, err = view.ParseContent(ctx, serviceGrpcTemplate, tplData); err == nil {
+		serviceGrpcValue = tmpServiceGrpc
+		serviceGrpcValue, err = common.TrimBreak(serviceGrpcValue)
+	} else {
+		return
+	}
+
 	routerKey := "router"
 	routerValue := ""
 	var tmpRouter string
@@ -224,6 +358,46 @@ func prepareTemplateData(ctx context.Context, table *common.TableDef, genOptions
 		return
 	}
 
+	auditKey := "audit"
+	auditValue := ""
+	var tmpAudit string
+	if tmpAudit, err = view.ParseContent(ctx, auditTemplate, tplData); err == nil {
+		auditValue = tmpAudit
+		auditValue, err = common.TrimBreak(auditValue)
+	} else {
+		return
+	}
+
+	exportKey := "export"
+	exportValue := ""
+	var tmpExport string
+	if tmpExport, err = view.ParseContent(ctx, exportTemplate, tplData); err == nil {
+		exportValue = tmpExport
+		exportValue, err = common.TrimBreak(exportValue)
+	} else {
+		return
+	}
+
+	importerKey := "importer"
+	importerValue := ""
+	var tmpImporter string
+	if tmpImporter, err = view.ParseContent(ctx, importerTemplate, tplData); err == nil {
+		importerValue = tmpImporter
+		importerValue, err = common.TrimBreak(importerValue)
+	} else {
+		return
+	}
+
+	cursorKey := "cursor"
+	cursorValue := ""
+	var tmpCursor string
+	if tmpCursor, err = view.ParseContent(ctx, cursorTemplate, tplData); err == nil {
+		cursorValue = tmpCursor
+		cursorValue, err = common.TrimBreak(cursorValue)
+	} else {
+		return
+	}
+
 	jsApiKey := "jsApi"
 	jsApiValue := ""
 	var tmpJsApi string
@@ -249,6 +423,41 @@ func prepareTemplateData(ctx context.Context, table *common.TableDef, genOptions
 		return
 	}
 
+	tsApiKey := "tsApi"
+	tsApiValue := ""
+	var tmpTsApi string
+	if tmpTsApi, err = view.ParseContent(ctx, tsApiTemplate, tplData); err == nil {
+		tsApiValue = tmpTsApi
+		tsApiValue, err = common.TrimBreak(tsApiValue)
+	} else {
+		return
+	}
+
+	tsRequestKey := "tsRequest"
+	tsRequestValue := ""
+	var tmpTsRequest string
+	if tmpTsRequest, err = view.ParseContent(ctx, tsRequestTemplate, tplData); err == nil {
+		tsRequestValue = tmpTsRequest
+		tsRequestValue, err = common.TrimBreak(tsRequestValue)
+	} else {
+		return
+	}
+
+	reactKey := "react"
+	reactValue := ""
+	var tmpReact string
+	reactTemplateContent := listReactTemplate
+	if table.TemplateCategory == "tree" {
+		//树表
+		reactTemplateContent = treeReactTemplate
+	}
+	if tmpReact, err = view.ParseContent(ctx, reactTemplateContent, tplData); err == nil {
+		reactValue = tmpReact
+		reactValue, err = common.TrimBreak(reactValue)
+	} else {
+		return
+	}
+
 	data = g.MapStrStr{
 		entityKey:            entityValue,
 		modelKey:             modelValue,
@@ -257,16 +466,123 @@ func prepareTemplateData(ctx context.Context, table *common.TableDef, genOptions
 		controllerKey:        controllerValue,
 		serviceKey:           serviceValue,
 		serviceCacheProxyKey: serviceCacheProxyValue,
+		smartcacheKey:        smartcacheValue,
+		smartcacheMemoryKey:  smartcacheMemoryValue,
+		smartcacheGfCacheKey: smartcacheGfCacheValue,
+		serviceTestKey:       serviceTestValue,
+		gatewayTestKey:       gatewayTestValue,
+		testdbKey:            testdbValue,
+		serviceMockKey:       serviceMockValue,
+		serviceGrpcKey:       serviceGrpcValue,
 		routerKey:            routerValue,
 		protobufKey:          protobufValue,
 		providerKey:          providerValue,
 		sqlKey:               sqlValue,
+		auditKey:             auditValue,
+		exportKey:            exportValue,
+		importerKey:          importerValue,
+		cursorKey:            cursorValue,
 		jsApiKey:             jsApiValue,
 		vueKey:               vueValue,
+		tsApiKey:             tsApiValue,
+		tsRequestKey:         tsRequestValue,
+		reactKey:             reactValue,
+	}
+	if table.Audit {
+		if err = prepareHistoryTemplateData(ctx, table, genOptions, data); err != nil {
+			return
+		}
+	}
+	if len(table.Children) > 0 || len(table.ManyToMany) > 0 {
+		err = prepareLinkedTablesTemplateData(ctx, table, genOptions, data)
 	}
 	return
 }
 
+// 获取子表及多对多关联表生成所需数据，其 entity/dao 复用模板生成到当前表的 package 下，供当前表 service 在同一事务中读写
+// key 为 "linkedEntity:表文件名" 等形式
+// 子表（或有 yaml 定义的关联表）与当前表在同一 package 下时，其自身生成的 entity/dao 即为同一文件，不再生成副本，以免相互覆盖
+func prepareLinkedTablesTemplateData(ctx context.Context, table *common.TableDef, genOptions *common.GenOptions, data g.MapStrStr) (err error) {
+	view := common.TemplateEngine()
+	templates := g.MapStrStr{
+		"linkedEntity":      entityTemplate,
+		"linkedDao":         daoTemplate,
+		"linkedDaoInternal": daoInternalTemplate,
+	}
+	linkedTables := make([]*common.TableDef, 0, len(table.Children)+len(table.ManyToMany))
+	for _, child := range table.Children {
+		if sharesPackage(table, child.Table) {
+			continue
+		}
+		linkedTables = append(linkedTables, child.Table)
+	}
+	for _, m := range table.ManyToMany {
+		// 关联表无需 yaml 定义，有定义时按其自身的 package 判断
+		if common.TableDefYamlExists(m.JoinTableName, genOptions.YamlInputPath) {
+			var joinTable *common.TableDef
+			joinTable, err = common.LoadTableDefYaml(ctx, m.JoinTableName, genOptions.YamlInputPath, genOptions.GoModuleName, make(map[string]*common.TableDef))
+			if err != nil {
+				return
+			}
+			if sharesPackage(table, joinTable) && joinTable.GoFileName == m.JoinTable.GoFileName {
+				continue
+			}
+		}
+		linkedTables = append(linkedTables, m.JoinTable)
+	}
+	var tmp string
+	for _, linkedTable := range linkedTables {
+		t := *linkedTable
+		// 生成到当前表的 package 下，不生成其自身的关联表、子表及多对多关联
+		t.BackendPackage = table.BackendPackage
+		t.SeparatePackage = table.SeparatePackage
+		t.GoFileName = table.GoFileName
+		t.RelatedTables = nil
+		t.Children = nil
+		t.ManyToMany = nil
+		linkedData := g.Map{"table": &t, "options": genOptions}
+		for key, content := range templates {
+			if tmp, err = view.ParseContent(ctx, content, linkedData); err != nil {
+				return
+			}
+			if data[key+":"+linkedTable.GoFileName], err = common.TrimBreak(tmp); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// sharesPackage 两个表的 entity/dao 是否生成在同一 package 下（均未分包且 backendPackage 相同）
+func sharesPackage(table *common.TableDef, other *common.TableDef) bool {
+	return !table.SeparatePackage && !other.SeparatePackage && table.BackendPackage == other.BackendPackage
+}
+
+// 获取数据变更历史表生成所需数据，历史表复用 entity/dao 模板
+func prepareHistoryTemplateData(ctx context.Context, table *common.TableDef, genOptions *common.GenOptions, data g.MapStrStr) (err error) {
+	historyData := g.Map{"table": table.HistoryTable, "options": genOptions}
+	view := common.TemplateEngine()
+	templates := g.MapStrStr{
+		"historyEntity":      entityTemplate,
+		"historyDao":         daoTemplate,
+		"historyDaoInternal": daoInternalTemplate,
+	}
+	var tmp string
+	for key, content := range templates {
+		if tmp, err = view.ParseContent(ctx, content, historyData); err != nil {
+			return
+		}
+		if data[key], err = common.TrimBreak(tmp); err != nil {
+			return
+		}
+	}
+	if tmp, err = view.ParseContent(ctx, historySqlTemplate, g.Map{"table": table, "options": genOptions}); err != nil {
+		return
+	}
+	data["historySql"], err = common.TrimBreak(tmp)
+	return
+}
+
 // 生成代码文件
 func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.GenOptions) error {
 	var (
@@ -274,6 +590,8 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 		path       string
 		pbPath     string
 		triplePath string
+		grpcPath   string
+		jsonPath   string
 		err        error
 	)
 	//获取当前运行时目录
@@ -339,13 +657,7 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 			} else {
 				path = strings.Join([]string{curDir, "/", packageName, "/model/", goFileName, ".go"}, "")
 			}
-			if !table.IsRpc {
-				err = common.WriteFile(path, code, table.Overwrite)
-			} else if table.Overwrite {
-				if gfile.Exists(path) {
-					_ = gfile.Remove(path)
-				}
-			}
+			err = common.WriteFile(path, code, table.Overwrite)
 		case "router":
 			if genOptions.ServiceOnly {
 				break
@@ -363,11 +675,16 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 				path = strings.Join([]string{curDir, "/", packageName, "/proto"}, "")
 			}
 			if table.IsRpc {
-				err = common.WriteFile(path+"/"+goFileName+".proto", code, table.Overwrite)
+				// 已部署的 rpc 调用方依赖字段编号，重新生成时沿用上一次 proto 文件中的编号
+				protoFile := path + "/" + goFileName + ".proto"
+				if gfile.Exists(protoFile) {
+					code = protobuf.StabilizeFieldNumbers(code, gfile.GetContents(protoFile))
+				}
+				err = common.WriteFile(protoFile, code, table.Overwrite)
 				if err != nil {
 					return err
 				}
-				err = protobuf.CallProtoc(curDir, table.BackendPackage, table.GoFileName, table.SeparatePackage)
+				err = protobuf.CompileProto(ctx, curDir, packageName, goFileName, table.SeparatePackage, table.IsGrpc)
 				if err != nil {
 					return err
 				}
@@ -378,9 +695,13 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 				if table.SeparatePackage {
 					pbPath = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/model/", goFileName, ".pb.go"}, "")
 					triplePath = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/model/", goFileName, "_triple.pb.go"}, "")
+					grpcPath = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/model/", goFileName, "_grpc.pb.go"}, "")
+					jsonPath = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/model/", goFileName, ".pb.json.go"}, "")
 				} else {
 					pbPath = strings.Join([]string{curDir, "/", packageName, "/model/", goFileName, ".pb.go"}, "")
 					triplePath = strings.Join([]string{curDir, "/", packageName, "/model/", goFileName, "_triple.pb.go"}, "")
+					grpcPath = strings.Join([]string{curDir, "/", packageName, "/model/", goFileName, "_grpc.pb.go"}, "")
+					jsonPath = strings.Join([]string{curDir, "/", packageName, "/model/", goFileName, ".pb.json.go"}, "")
 				}
 				if gfile.Exists(pbPath) {
 					_ = gfile.Remove(pbPath)
@@ -388,6 +709,12 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 				if gfile.Exists(triplePath) {
 					_ = gfile.Remove(triplePath)
 				}
+				if gfile.Exists(grpcPath) {
+					_ = gfile.Remove(grpcPath)
+				}
+				if gfile.Exists(jsonPath) {
+					_ = gfile.Remove(jsonPath)
+				}
 			}
 		case "provider":
 			if table.SeparatePackage {
@@ -418,6 +745,127 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 				}
 				err = common.WriteFile(path, code, table.Overwrite)
 			}
+		case "smartcache":
+			// 智能缓存工具全应用共用一份
+			if genOptions.SmartCache {
+				path = strings.Join([]string{curDir, "/library/smartcache/smartcache.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "smartcacheMemory":
+			if genOptions.SmartCache {
+				path = strings.Join([]string{curDir, "/library/smartcache/memory.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "smartcacheGfCache":
+			// 只有使用 gf-cache 缓存后端时才依赖 gf-cache
+			if genOptions.SmartCache {
+				path = strings.Join([]string{curDir, "/library/smartcache/gfcache.go"}, "")
+				if genOptions.CacheBackend == common.CacheBackendGfCache {
+					err = common.WriteFile(path, code, table.Overwrite)
+				} else if table.Overwrite && gfile.Exists(path) {
+					_ = gfile.Remove(path)
+				}
+			}
+		case "serviceTest":
+			if genOptions.WithTests {
+				if table.SeparatePackage {
+					path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/service/", goFileName + "_test", ".go"}, "")
+				} else {
+					path = strings.Join([]string{curDir, "/", packageName, "/service/", goFileName + "_test", ".go"}, "")
+				}
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "gatewayTest":
+			// 网关代替控制器时才生成网关参数校验测试
+			if table.SeparatePackage {
+				path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/api/", goFileName + "_gateway_test", ".go"}, "")
+			} else {
+				path = strings.Join([]string{curDir, "/", packageName, "/api/", goFileName + "_gateway_test", ".go"}, "")
+			}
+			if genOptions.WithTests && !genOptions.ServiceOnly && table.IsRpc && table.RpcHttp {
+				err = common.WriteFile(path, code, table.Overwrite)
+			} else if table.Overwrite {
+				if gfile.Exists(path) {
+					_ = gfile.Remove(path)
+				}
+			}
+		case "testdb":
+			// 单元测试使用的临时数据库工具全应用共用一份
+			if genOptions.WithTests {
+				path = strings.Join([]string{curDir, "/library/testdb/testdb.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "serviceGrpc":
+			if table.SeparatePackage {
+				path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/service/", goFileName + "_grpc", ".go"}, "")
+			} else {
+				path = strings.Join([]string{curDir, "/", packageName, "/service/", goFileName + "_grpc", ".go"}, "")
+			}
+			if table.IsRpc && table.IsGrpc {
+				err = common.WriteFile(path, code, table.Overwrite)
+			} else if table.Overwrite {
+				if gfile.Exists(path) {
+					_ = gfile.Remove(path)
+				}
+			}
+		case "serviceMock":
+			if genOptions.WithMocks {
+				if table.SeparatePackage {
+					path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/service/mock/", goFileName, ".go"}, "")
+				} else {
+					path = strings.Join([]string{curDir, "/", packageName, "/service/mock/", goFileName, ".go"}, "")
+				}
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "audit":
+			// 审计字段操作人 hook 全应用共用一份
+			if table.HasCreatedBy || table.HasUpdatedBy || table.Audit {
+				path = strings.Join([]string{curDir, "/library/audit/audit.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "export":
+			// 列表导出工具全应用共用一份
+			if table.Export {
+				path = strings.Join([]string{curDir, "/library/export/export.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "importer":
+			// 数据导入工具全应用共用一份
+			if table.Import {
+				path = strings.Join([]string{curDir, "/library/importer/importer.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "cursor":
+			// 游标分页工具全应用共用一份
+			if table.IsCursorPagination {
+				path = strings.Join([]string{curDir, "/library/cursor/cursor.go"}, "")
+				err = common.WriteFile(path, code, table.Overwrite)
+			}
+		case "historyEntity":
+			if table.SeparatePackage {
+				path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/model/entity/", table.HistoryTable.GoFileName, ".go"}, "")
+			} else {
+				path = strings.Join([]string{curDir, "/", packageName, "/model/entity/", table.HistoryTable.GoFileName, ".go"}, "")
+			}
+			err = common.WriteFile(path, code, table.Overwrite)
+		case "historyDao":
+			if table.SeparatePackage {
+				path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/service/internal/dao/", table.HistoryTable.GoFileName, ".go"}, "")
+			} else {
+				path = strings.Join([]string{curDir, "/", packageName, "/service/internal/dao/", table.HistoryTable.GoFileName, ".go"}, "")
+			}
+			err = common.WriteFile(path, code, table.Overwrite)
+		case "historyDaoInternal":
+			if table.SeparatePackage {
+				path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, "/service/internal/dao/internal/", table.HistoryTable.GoFileName, ".go"}, "")
+			} else {
+				path = strings.Join([]string{curDir, "/", packageName, "/service/internal/dao/internal/", table.HistoryTable.GoFileName, ".go"}, "")
+			}
+			err = common.WriteFile(path, code, table.Overwrite)
+		case "historySql":
+			// 历史表建表语句，需手工在数据库中执行
+			path = strings.Join([]string{curDir, "/data/gen_sql/", packageName, "/", table.HistoryTable.GoFileName, ".sql"}, "")
+			err = common.WriteFile(path, code, table.Overwrite)
 		case "sql":
 			if g.IsEmpty(frontDir) {
 				break
@@ -433,7 +881,7 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 				}
 			}
 		case "vue":
-			if g.IsEmpty(frontDir) {
+			if g.IsEmpty(frontDir) || genOptions.FrontendType == common.FrontendTypeArco {
 				break
 			}
 			path = strings.Join([]string{frontDir, "/src/views/", table.FrontendPath, "/", table.FrontendFileName, "/list/index.vue"}, "")
@@ -442,7 +890,7 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 			}
 			err = common.WriteFile(path, code, table.Overwrite)
 		case "jsApi":
-			if g.IsEmpty(frontDir) {
+			if g.IsEmpty(frontDir) || genOptions.FrontendType == common.FrontendTypeArco {
 				break
 			}
 			path = strings.Join([]string{frontDir, "/src/api/", table.FrontendPath, "/", table.FrontendFileName, ".js"}, "")
@@ -450,6 +898,52 @@ func doGenCode(ctx context.Context, table *common.TableDef, genOptions *common.G
 				path = strings.Join([]string{frontDir, "/src/api/plugins/", table.FrontendPath, "/", table.FrontendFileName, ".js"}, "")
 			}
 			err = common.WriteFile(path, code, table.Overwrite)
+		case "react":
+			if g.IsEmpty(frontDir) || genOptions.FrontendType != common.FrontendTypeArco {
+				break
+			}
+			path = strings.Join([]string{frontDir, "/src/pages/", table.FrontendPath, "/", table.FrontendFileName, "/list/index.tsx"}, "")
+			if gstr.ContainsI(table.BackendPackage, "plugins") {
+				path = strings.Join([]string{frontDir, "/src/pages/plugins/", table.FrontendPath, "/", table.FrontendFileName, "/list/index.tsx"}, "")
+			}
+			err = common.WriteFile(path, code, table.Overwrite)
+		case "tsApi":
+			if g.IsEmpty(frontDir) || genOptions.FrontendType != common.FrontendTypeArco {
+				break
+			}
+			path = strings.Join([]string{frontDir, "/src/api/", table.FrontendPath, "/", table.FrontendFileName, ".ts"}, "")
+			if gstr.ContainsI(table.BackendPackage, "plugins") {
+				path = strings.Join([]string{frontDir, "/src/api/plugins/", table.FrontendPath, "/", table.FrontendFileName, ".ts"}, "")
+			}
+			err = common.WriteFile(path, code, table.Overwrite)
+		case "tsRequest":
+			if g.IsEmpty(frontDir) || genOptions.FrontendType != common.FrontendTypeArco {
+				break
+			}
+			// 各页面共用的请求及工具函数，仅在不存在时生成，之后可按项目实际情况修改
+			path = strings.Join([]string{frontDir, "/src/utils/request.ts"}, "")
+			err = common.WriteFile(path, code, false)
+		default:
+			// 子表及多对多关联表的 entity/dao，生成到当前表的 package 下，key 为 "linkedEntity:表文件名" 等形式
+			keys := strings.SplitN(key, ":", 2)
+			if len(keys) != 2 {
+				break
+			}
+			linkedDirs := g.MapStrStr{
+				"linkedEntity":      "/model/entity/",
+				"linkedDao":         "/service/internal/dao/",
+				"linkedDaoInternal": "/service/internal/dao/internal/",
+			}
+			linkedDir, found := linkedDirs[keys[0]]
+			if !found {
+				break
+			}
+			if table.SeparatePackage {
+				path = strings.Join([]string{curDir, "/", packageName, "/", goFileName, linkedDir, keys[1], ".go"}, "")
+			} else {
+				path = strings.Join([]string{curDir, "/", packageName, linkedDir, keys[1], ".go"}, "")
+			}
+			err = common.WriteFile(path, code, table.Overwrite)
 		}
 	}
 	//生成对应的模块路由
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/WesleyWu/gf-codegen/common"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+const fixtureGoMod = `module example.com/proj
+
+go 1.18
+
+require (
+	github.com/gogf/gf/contrib/drivers/mysql/v2 v2.2.5
+	github.com/gogf/gf/contrib/drivers/sqlite/v2 v2.2.5
+	github.com/gogf/gf/v2 v2.2.5
+	github.com/xuri/excelize/v2 v2.11.0
+)
+`
+
+// genFixtureProject 将 testdata 下的表定义生成到临时项目中，返回项目目录
+func genFixtureProject(t *testing.T, fixture string, tables []string, genOptions *common.GenOptions) string {
+	t.Helper()
+	fixtureDir, err := filepath.Abs(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	projDir := t.TempDir()
+	if err = gfile.Copy(fixtureDir, filepath.Join(projDir, genOptions.YamlInputPath)); err != nil {
+		t.Fatal(err)
+	}
+	if err = gfile.PutContents(filepath.Join(projDir, "go.mod"), fixtureGoMod); err != nil {
+		t.Fatal(err)
+	}
+	// 代码生成以当前工作目录为项目根目录
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(projDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+	ctx := context.Background()
+	for _, table := range tables {
+		if err = GenCodeByTableDefYaml(ctx, table, genOptions); err != nil {
+			t.Fatalf("生成 %s 失败：%v", table, err)
+		}
+	}
+	return projDir
+}
+
+// runGo 在项目目录下执行 go 命令，失败时输出命令结果
+func runGo(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %v 失败：%v\n%s", args, err, out)
+	}
+}
+
+// TestGenDefaultLayout 未分包（separatePackage=false）时所有表共用一个 service 包，生成的代码及单元测试须能编译通过并运行
+func TestGenDefaultLayout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("生成项目并编译运行较慢，-short 时跳过")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("未找到 go 命令")
+	}
+	projDir := genFixtureProject(t, "default_layout",
+		[]string{"demo_customer", "demo_tag", "demo_order", "demo_order_item", "demo_category"},
+		&common.GenOptions{
+			YamlInputPath: "manifest/config/codegen_conf",
+			GoModuleName:  "example.com/proj",
+			ServiceOnly:   true,
+			WithTests:     true,
+		})
+	if !gfile.Exists(filepath.Join(projDir, "app/demo/shop/service/order.go")) {
+		t.Fatal("未分包时 service 应生成在 app/demo/shop/service 下")
+	}
+	// 子表与当前表同包时，当前表不应以子表副本覆盖子表自身生成的 entity
+	if !strings.Contains(gfile.GetContents(filepath.Join(projDir, "app/demo/shop/model/entity/order_item.go")), "with:id=tag_id") {
+		t.Fatal("子表 entity 被当前表生成的副本覆盖，缺少关联表字段")
+	}
+	runGo(t, projDir, "mod", "tidy")
+	runGo(t, projDir, "vet", "./...")
+	runGo(t, projDir, "test", "./...")
+}
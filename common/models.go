@@ -4,10 +4,14 @@ import (
 	"context"
 	"github.com/gogf/gf/v2/container/gmap"
 	"github.com/gogf/gf/v2/container/gset"
+	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/gogf/gf/v2/text/gstr"
+	"sort"
+	"strconv"
+	"time"
 )
 
 const (
@@ -48,11 +52,25 @@ type TableDef struct { // 表属性
 	TreeCode             string                `yaml:"treeCode,omitempty"`         // tree类型对应的当前记录键字段
 	TreeParentCode       string                `yaml:"treeParentCode,omitempty"`   // tree类型对应的父记录查询字段
 	TreeName             string                `yaml:"treeName,omitempty"`         // tree类型对应的当前记录显示字段
+	TreePathColumnName   string                `yaml:"treePathColumn,omitempty"`   // tree类型可选的物化路径字段（字符串类型），保存从根节点到当前节点的 treeCode 路径，如 /1/5/12/
+	TreeLevelColumnName  string                `yaml:"treeLevelColumn,omitempty"`  // tree类型可选的层级字段（整数类型），根节点为 1，须与 treePathColumn 同时使用
 	Overwrite            bool                  `yaml:"overwrite,omitempty"`        // 生成时是否覆盖现有代码和菜单设置
 	SortColumn           string                `yaml:"sortColumn,omitempty"`       // 排序字段
 	SortType             string                `yaml:"sortType,omitempty"`         // 排序方式 asc/desc
+	VersionColumnName    string                `yaml:"versionColumn,omitempty"`    // 乐观锁版本字段（整数类型），为空则不启用乐观锁
+	Audit                bool                  `yaml:"audit,omitempty"`            // 是否记录数据变更历史（生成 {table}_history 表）
+	Export               bool                  `yaml:"export,omitempty"`           // 是否生成列表数据导出（CSV/XLSX）接口
+	Import               bool                  `yaml:"import,omitempty"`           // 是否生成数据导入（CSV/XLSX）接口
+	Pagination           string                `yaml:"pagination,omitempty"`       // 列表分页方式 page/cursor，缺省为 page；cursor 为基于排序字段+主键的游标分页，适用于大表
+	KeywordSearch        *KeywordSearchDef     `yaml:"keywordSearch,omitempty"`    // 关键字搜索定义，为空则不生成关键字搜索
+	Children             []*ChildTableDef      `yaml:"children,omitempty"`         // 主从表（一对多）的子表定义，新增/修改时与主表记录在同一事务中保存
+	ManyToMany           []*ManyToManyDef      `yaml:"manyToMany,omitempty"`       // 多对多关联定义，新增/修改时与主表记录在同一事务中同步关联表
+	Aggregations         *AggregationsDef      `yaml:"aggregations,omitempty"`     // 统计聚合定义，为空则不生成统计接口
+	Cache                *CacheDef             `yaml:"cache,omitempty"`            // 智能缓存策略定义，仅在 smartCache 时有效，为空则各读方法均按缺省策略缓存
 	ShowDetail           bool                  `yaml:"showDetail,omitempty"`       // 是否有显示详情功能
-	IsRpc                bool                  `yaml:"isRpc,omitempty"`            // 是否生成dubbogo rpc代码
+	IsRpc                bool                  `yaml:"isRpc,omitempty"`            // 是否生成rpc代码
+	RpcFramework         string                `yaml:"rpcFramework,omitempty"`     // rpc 框架 dubbo/grpc，缺省为 dubbo（dubbo-go triple 协议）；grpc 为 grpc-go，不依赖注册中心
+	RpcHttp              bool                  `yaml:"rpcHttp,omitempty"`          // rpc 服务是否在 proto 中生成 google.api.http 注解，并以 http 网关代替 api 控制器
 	SeparatePackage      bool                  `yaml:"separatePackage,omitempty"`  // 是否将代码生成到单独的目录下
 	RpcPort              int                   `yaml:"rpcPort"`                    // rpc provider 服务侦听端口
 	CreateTime           *gtime.Time           `yaml:"createTime,omitempty"`       // 当前配置初始生成时间
@@ -65,9 +83,25 @@ type TableDef struct { // 表属性
 	HasConversion        bool                  `yaml:"-"`                          // 是否需要字段值转换
 	CreatedAtColumn      *ColumnDef            `yaml:"-"`                          // created_at字段
 	CreatedByColumn      *ColumnDef            `yaml:"-"`                          // created_by字段
+	UpdatedAtColumn      *ColumnDef            `yaml:"-"`                          // updated_at字段
+	UpdatedByColumn      *ColumnDef            `yaml:"-"`                          // updated_by字段
 	HasCreatedBy         bool                  `yaml:"-"`                          // 是否有created_by字段
 	HasUpdatedBy         bool                  `yaml:"-"`                          // 是否有updated_by字段
+	VersionColumn        *ColumnDef            `yaml:"-"`                          // 乐观锁版本字段
+	IsVersionInEdit      bool                  `yaml:"-"`                          // 乐观锁版本字段是否出现在 EditColumn 中
+	IsPkInAdd            bool                  `yaml:"-"`                          // 主键是否出现在 AddColumn 中
 	IsPkInEdit           bool                  `yaml:"-"`                          // 主键是否出现在 EditColumn 中
+	PkColumn             *ColumnDef            `yaml:"-"`                          // 主键列信息（单字段主键）
+	IsCursorPagination   bool                  `yaml:"-"`                          // 列表是否使用游标分页
+	IsGrpc               bool                  `yaml:"-"`                          // rpc 框架是否为 grpc
+	SortColumnDef        *ColumnDef            `yaml:"-"`                          // 排序字段列信息，仅当 IsCursorPagination 为 true 时有效
+	TreeCodeColumn       *ColumnDef            `yaml:"-"`                          // tree类型的当前记录键字段（即主键）
+	TreeParentColumn     *ColumnDef            `yaml:"-"`                          // tree类型的父记录键字段
+	TreePathColumn       *ColumnDef            `yaml:"-"`                          // tree类型的物化路径字段，为空则逐层查询
+	TreeLevelColumn      *ColumnDef            `yaml:"-"`                          // tree类型的层级字段
+	IsTreeParentInEdit   bool                  `yaml:"-"`                          // tree类型的父记录键字段是否出现在 EditColumn 中（修改时须做循环检测）
+	HistoryTable         *TableDef             `yaml:"-"`                          // 数据变更历史表，仅当 Audit 为 true 时有效
+	ImportRelatedTables  []*TableDef           `yaml:"-"`                          // 新增字段直接关联的表（去重），导入时用于根据显示值反查关联表主键
 	PkColumns            map[string]*ColumnDef `yaml:"-"`                          // 主键列信息（可以有多个）
 	ColumnMap            map[string]*ColumnDef `yaml:"-"`                          // 所有列的map，key为 Name
 	Columns              []*ColumnDef          `yaml:"-"`                          // 数据库表所有字段
@@ -88,9 +122,11 @@ type TableDef struct { // 表属性
 	HasVirtualQueries    bool                  `yaml:"-"`                          // 是否有虚拟字段参与查询
 	VirtualQueryRelated  map[string]*TableDef  `yaml:"-"`                          // 虚拟字段参与查询的关联表
 	FkColumnNameSet      *gset.StrSet          `yaml:"-"`                          // 所有的外键字段
-	FkColumnsNotInList   []*ColumnDef          `yaml:"-"`                          // 没有出现在 list 列表中的 ForeignKeyColumnName 字段
+	FkColumnsNotInList   []*ColumnDef          `yaml:"-"`                          // 没有出现在 list 列表中的 ForeignKeyColumnName 字段（有子表或多对多关联时还包括主键）
 	AllRelatedTableMap   *gmap.ListMap         `yaml:"-"`                          // 所有的被关联表map，包含二级嵌套和三级嵌套
 	AllRelatedTables     []interface{}         `yaml:"-"`                          // 所有的被关联表slice，包含二级嵌套和三级嵌套
+	ManyToManyRemotes    []*TableDef           `yaml:"-"`                          // 多对多关联的远端表（去重）
+	ManyToManyListTables []*TableDef           `yaml:"-"`                          // 前端需要单独生成列表选项接口的远端表（不在 AllRelatedTables 中且不是当前表）
 }
 
 type ColumnDef struct { // 字段基本属性
@@ -125,6 +161,7 @@ type ColumnDef struct { // 字段基本属性
 	CombinedTableClass     string                `yaml:"-"`                                // 关联、虚拟值字段所属实际表的ClassName
 	CombinedHtmlTableClass string                `yaml:"-"`                                // 关联、虚拟值字段所属实际表的前端类名（用于构建字典填充和下拉框内容延迟填充）
 	CombinedHtmlField      string                `yaml:"-"`                                // 关联、虚拟字段的前端变量名
+	RelatedTable           *TableDef             `yaml:"-"`                                // 直接关联（非虚拟字段）的关联表
 }
 
 type ListColumnDef struct {
@@ -135,6 +172,7 @@ type ListColumnDef struct {
 	MinWidth          int        `yaml:"minWidth,omitempty"`          // 列最小显示宽度
 	IsFixed           bool       `yaml:"isFixed,omitempty"`           // 在列表中是否固定在最左边
 	IsOverflowTooltip bool       `yaml:"isOverflowTooltip,omitempty"` // 在列表中是否省略一行显示不下的内容并将完整内容放在 tooltip 中
+	IsSortable        bool       `yaml:"sortable,omitempty"`          // 是否允许按此字段排序（列表表头可点击排序），虚拟字段不能排序
 	Base              *ColumnDef `yaml:"-"`                           // 对应字段
 	Comment           string     `yaml:"-"`                           // 字段描述
 	GoType            string     `yaml:"-"`                           // go字段类型，可以不填（会根据ColumnType自动判断）
@@ -166,17 +204,22 @@ type EditColumnDef struct {
 }
 
 type QueryColumnDef struct {
-	Name            string     `yaml:"-"`                   // 字段名
-	Sort            int        `yaml:"sort"`                // 排序
-	HtmlType        string     `yaml:"htmlType,omitempty"`  // 前端控件类型
-	QueryType       string     `yaml:"queryType,omitempty"` // 查询类型 EQ|LIKE|BETWEEN
-	FieldValidation string     `yaml:"-"`                   // 查询请求中的参数验证规则
-	FieldConversion string     `yaml:"-"`                   // 查询请求中的必要类型转换
-	Base            *ColumnDef `yaml:"-"`                   // 对应字段
-	Comment         string     `yaml:"-"`                   // 字段描述（从字段基本属性中复制）
-	GoType          string     `yaml:"-"`                   // go字段类型（从字段基本属性中复制）
-	GoField         string     `yaml:"-"`                   // go字段变量名（从字段基本属性中复制）
-	HtmlField       string     `yaml:"-"`                   // 字段前端变量名（从字段基本属性中复制）
+	Name            string     `yaml:"-"`                    // 字段名
+	Sort            int        `yaml:"sort"`                 // 排序
+	HtmlType        string     `yaml:"htmlType,omitempty"`   // 前端控件类型
+	QueryType       string     `yaml:"queryType,omitempty"`  // 查询类型 EQ|NE|GT|GTE|LT|LTE|LIKE|PREFIX|SUFFIX|BETWEEN|IN|NOT IN|IS NULL|IS NOT NULL
+	IsIgnoreCase    bool       `yaml:"ignoreCase,omitempty"` // 是否忽略大小写（仅用于字符串字段的 EQ|NE|LIKE|PREFIX|SUFFIX 查询）
+	FieldValidation string     `yaml:"-"`                    // 查询请求中的参数验证规则
+	FieldConversion string     `yaml:"-"`                    // 查询请求中的必要类型转换
+	IsArrayQuery    bool       `yaml:"-"`                    // 查询参数是否为数组（BETWEEN|IN|NOT IN）
+	IsNullQuery     bool       `yaml:"-"`                    // 是否为空值查询（IS NULL|IS NOT NULL），查询参数为 bool，为 true 时才作为查询条件
+	ReqGoType       string     `yaml:"-"`                    // 查询请求参数的 go 类型
+	ReqProtoType    string     `yaml:"-"`                    // 查询请求参数的 proto 类型
+	Base            *ColumnDef `yaml:"-"`                    // 对应字段
+	Comment         string     `yaml:"-"`                    // 字段描述（从字段基本属性中复制）
+	GoType          string     `yaml:"-"`                    // go字段类型（从字段基本属性中复制）
+	GoField         string     `yaml:"-"`                    // go字段变量名（从字段基本属性中复制）
+	HtmlField       string     `yaml:"-"`                    // 字段前端变量名（从字段基本属性中复制）
 }
 
 type DetailColumnDef struct {
@@ -192,6 +235,101 @@ type DetailColumnDef struct {
 	HtmlField  string     `yaml:"-"`                    // 字段前端变量名（从字段基本属性中复制）
 }
 
+type KeywordSearchDef struct { // 关键字搜索定义，一个关键字匹配多个字段中的任一字段
+	Columns        []string     `yaml:"columns"`        // 参与搜索的字段名，可以是虚拟字段（通过关联表子查询匹配）
+	Mode           string       `yaml:"mode,omitempty"` // 匹配方式 like/fulltext，缺省为 like；fulltext 对本表字段使用 MATCH ... AGAINST，需要这些字段上有 FULLTEXT 索引
+	RealColumns    []*ColumnDef `yaml:"-"`              // 参与搜索的本表字段
+	VirtualColumns []*ColumnDef `yaml:"-"`              // 参与搜索的虚拟字段
+	Comments       string       `yaml:"-"`              // 参与搜索字段的描述，以 / 分隔，用于前端提示
+}
+
+type ChildTableDef struct { // 主从表（一对多）中的子表定义，子表通过外键参照当前表的主键
+	TableName            string           `yaml:"tableName"`            // 子表名，子表也须有对应的 yaml 定义
+	ForeignKeyColumnName string           `yaml:"foreignKeyColumnName"` // 子表中参照当前表主键的外键字段
+	Name                 string           `yaml:"name,omitempty"`       // 子表记录列表的字段名，缺省为子表业务名转小驼峰后加 List
+	Comment              string           `yaml:"comment,omitempty"`    // 子表描述，缺省为子表的功能名称
+	Table                *TableDef        `yaml:"-"`                    // 子表
+	FkColumn             *ColumnDef       `yaml:"-"`                    // 子表中的外键字段
+	ClassName            string           `yaml:"-"`                    // 子表记录在当前表 model 中的类名前缀，为当前表ClassName+子表ClassName
+	GoField              string           `yaml:"-"`                    // 子表记录列表的go字段名
+	HtmlField            string           `yaml:"-"`                    // 子表记录列表的前端变量名
+	EditColumns          []*EditColumnDef `yaml:"-"`                    // 子表可编辑字段（取自子表的 editColumns，不含主键和外键）
+}
+
+type ManyToManyDef struct { // 多对多关联定义，当前表与远端表通过关联表（join table）关联
+	JoinTableName         string     `yaml:"joinTableName"`         // 关联表名，关联表只需包含两个外键字段，无需 yaml 定义
+	LocalKeyColumnName    string     `yaml:"localKeyColumnName"`    // 关联表中参照当前表主键的字段
+	RemoteKeyColumnName   string     `yaml:"remoteKeyColumnName"`   // 关联表中参照远端表主键的字段
+	RemoteTableName       string     `yaml:"remoteTableName"`       // 远端表名，远端表须有对应的 yaml 定义
+	RemoteValueColumnName string     `yaml:"remoteValueColumnName"` // 远端表中用于显示的字段
+	Name                  string     `yaml:"name,omitempty"`        // 远端记录列表的字段名，缺省为远端表业务名转小驼峰后加 List
+	IdsName               string     `yaml:"idsName,omitempty"`     // 远端记录主键列表的字段名，缺省为远端表业务名转小驼峰后加 Ids
+	Comment               string     `yaml:"comment,omitempty"`     // 描述，缺省为远端表的功能名称
+	JoinTable             *TableDef  `yaml:"-"`                     // 关联表（根据外键字段生成的定义）
+	LocalKeyColumn        *ColumnDef `yaml:"-"`                     // 关联表中参照当前表主键的字段
+	RemoteKeyColumn       *ColumnDef `yaml:"-"`                     // 关联表中参照远端表主键的字段
+	RemoteTable           *TableDef  `yaml:"-"`                     // 远端表
+	RemoteValueColumn     *ColumnDef `yaml:"-"`                     // 远端表中用于显示的字段
+	ClassName             string     `yaml:"-"`                     // 远端记录在当前表 model 中的类名前缀，为当前表ClassName+远端表ClassName
+	GoField               string     `yaml:"-"`                     // 远端记录列表的go字段名
+	HtmlField             string     `yaml:"-"`                     // 远端记录列表的前端变量名
+	IdsGoField            string     `yaml:"-"`                     // 远端记录主键列表的go字段名
+	IdsHtmlField          string     `yaml:"-"`                     // 远端记录主键列表的前端变量名
+}
+
+type AggregationsDef struct { // 统计聚合定义，按分组字段统计指标，查询条件与列表查询相同
+	GroupBy []*AggregationGroupDef  `yaml:"groupBy"` // 可用的分组字段，请求中可以选择其中的一个或多个，缺省为全部
+	Metrics []*AggregationMetricDef `yaml:"metrics"` // 统计指标
+}
+
+type AggregationGroupDef struct { // 统计分组字段
+	Column     string     `yaml:"column"`               // 分组字段名
+	Name       string     `yaml:"name,omitempty"`       // 分组在返回结果中的字段名，缺省为字段的前端变量名
+	DateBucket string     `yaml:"dateBucket,omitempty"` // 时间字段的缺省分桶方式 day/week/month/year，缺省为 day，请求中可以另行指定
+	Comment    string     `yaml:"comment,omitempty"`    // 描述，缺省为字段描述
+	Base       *ColumnDef `yaml:"-"`                    // 对应字段
+	IsDate     bool       `yaml:"-"`                    // 是否时间字段（按 DateBucket 分桶，返回结果为字符串）
+	GoType     string     `yaml:"-"`                    // 返回结果中的go类型
+	ProtoType  string     `yaml:"-"`                    // 返回结果中的proto类型
+	GoField    string     `yaml:"-"`                    // 返回结果中的go字段名
+	HtmlField  string     `yaml:"-"`                    // 返回结果中的前端变量名
+}
+
+type AggregationMetricDef struct { // 统计指标
+	Func      string     `yaml:"func"`              // 聚合函数 count/sum/avg/min/max
+	Column    string     `yaml:"column,omitempty"`  // 统计字段名，须为数值字段；count 可以为空，表示统计记录数
+	Name      string     `yaml:"name,omitempty"`    // 指标在返回结果中的字段名，缺省为聚合函数加字段名，如 sumAmount；不指定字段的 count 为 count
+	Comment   string     `yaml:"comment,omitempty"` // 描述，缺省由聚合函数及字段描述生成
+	Base      *ColumnDef `yaml:"-"`                 // 对应字段，不指定字段的 count 为空
+	SqlFunc   string     `yaml:"-"`                 // SQL 聚合函数名
+	GoType    string     `yaml:"-"`                 // 返回结果中的go类型，count 为 int64，其余为 float64
+	ProtoType string     `yaml:"-"`                 // 返回结果中的proto类型
+	GoField   string     `yaml:"-"`                 // 返回结果中的go字段名
+	HtmlField string     `yaml:"-"`                 // 返回结果中的前端变量名
+}
+
+type CacheDef struct { // 智能缓存策略定义，methods 中未设置的项沿用表级的缺省值
+	TTL       string                     `yaml:"ttl,omitempty"`       // 缓存有效期，如 30s、5m、1h，缺省为不过期（直到数据变更时被清除）
+	Scope     string                     `yaml:"scope,omitempty"`     // 缓存作用域，为 ctx 中的变量名（如 userId、tenantId），不同取值的调用分别缓存；缺省为全部调用共用
+	Downgrade string                     `yaml:"downgrade,omitempty"` // 获取缓存锁超时时的降级策略 stale/error/fallback，缺省为 fallback
+	Methods   map[string]*CacheMethodDef `yaml:"methods,omitempty"`   // 各读方法的缓存策略，key为方法名 GetList/GetInfoById/DoGetList/DoGetOne/Aggregate
+	Policies  map[string]*CacheMethodDef `yaml:"-"`                   // 各读方法合并缺省值后的缓存策略，key为方法名
+}
+
+type CacheMethodDef struct { // 读方法的缓存策略
+	Disabled  bool   `yaml:"disabled,omitempty"`  // 是否不缓存
+	TTL       string `yaml:"ttl,omitempty"`       // 缓存有效期
+	Scope     string `yaml:"scope,omitempty"`     // 缓存作用域，为 none 时表示全部调用共用（用于覆盖表级的缺省值）
+	Downgrade string `yaml:"downgrade,omitempty"` // 获取缓存锁超时时的降级策略 stale/error/fallback
+	Fallback  string `yaml:"fallback,omitempty"`  // downgrade 为 fallback 时返回的结果（JSON），缺省为空结果
+	TtlMillis int64  `yaml:"-"`                   // 缓存有效期（毫秒），0 为不过期
+}
+
+// FallbackLiteral 降级结果的 Go 字符串字面量
+func (c *CacheMethodDef) FallbackLiteral() string {
+	return strconv.Quote(c.Fallback)
+}
+
 func (s *TableDef) SetVariableNames(goModuleName string) {
 	s.BackendPackage = gstr.TrimLeftStr(s.BackendPackage, "/")
 	s.BackendPackage = gstr.TrimRightStr(s.BackendPackage, "/")
@@ -214,6 +352,150 @@ func (s *TableDef) SetVariableNames(goModuleName string) {
 	s.FrontendPath = gstr.CaseKebab(s.FrontendModule)
 }
 
+// ServicePackage service 包的导入路径，未分包（separatePackage=false）时同一 backendPackage 下的表共用一个 service 包
+func (s *TableDef) ServicePackage() string {
+	if s.SeparatePackage {
+		return s.BackendPackage + "/" + s.GoFileName + "/service"
+	}
+	return s.BackendPackage + "/service"
+}
+
+// ProcessHistoryTable 根据当前表生成数据变更历史表 {table}_history 的定义
+// 历史表与当前表生成在同一个 package 下，记录每次变更前后的 JSON 快照、操作人及操作时间
+func (s *TableDef) ProcessHistoryTable(goModuleName string) error {
+	if !s.Audit {
+		return nil
+	}
+	if s.PkColumn == nil {
+		return gerror.Newf("表 %s 没有主键，无法记录数据变更历史", s.Name)
+	}
+	backendPackage := s.BackendPackage
+	if s.SeparatePackage {
+		backendPackage = backendPackage + "/" + s.GoFileName
+	}
+	history := &TableDef{
+		Name:             s.Name + "_history",
+		Comment:          s.Comment + "变更历史",
+		BackendPackage:   backendPackage,
+		FrontendModule:   s.FrontendModule,
+		TemplateCategory: "crud",
+		BusinessName:     s.BusinessName + "_history",
+		FunctionName:     s.FunctionName + "变更历史",
+		FunctionAuthor:   s.FunctionAuthor,
+		SortColumn:       "id",
+		SortType:         "desc",
+		CreateTime:       s.CreateTime,
+		UpdateTime:       s.UpdateTime,
+		HasTimeColumn:    true,
+		PkColumns:        make(map[string]*ColumnDef),
+		ColumnMap:        make(map[string]*ColumnDef),
+	}
+	history.SetVariableNames(goModuleName)
+	history.Columns = []*ColumnDef{
+		{Name: "id", Comment: "主键", SqlType: "bigint(20) unsigned", IsPk: true, IsIncrement: true},
+		{Name: "record_id", Comment: s.FunctionName + "主键", SqlType: s.PkColumn.SqlType, IsRequired: true},
+		{Name: "action", Comment: "操作类型 create/update/upsert/delete/change", SqlType: "varchar(16)", IsRequired: true},
+		{Name: "before_data", Comment: "变更前数据（JSON）", SqlType: "longtext"},
+		{Name: "after_data", Comment: "变更后数据（JSON）", SqlType: "longtext"},
+		{Name: "operator", Comment: "操作人", SqlType: "varchar(64)"},
+		{Name: "created_at", Comment: "操作时间", SqlType: "datetime"},
+	}
+	for i, column := range history.Columns {
+		column.Sort = i + 1
+		if err := column.SetColumnValues(); err != nil {
+			return err
+		}
+		if column.IsPk {
+			history.PkColumns[column.Name] = column
+			history.PkColumn = column
+		}
+		history.ColumnMap[column.Name] = column
+	}
+	history.CreatedAtColumn = history.ColumnMap["created_at"]
+	s.HistoryTable = history
+	return nil
+}
+
+// processTree 检查树表的 treeCode/treeParentCode 及可选的物化路径、层级字段
+// treeCode 须为主键，树操作接口（子树、祖先路径、移动、删除子树）均以主键作为节点标识
+func (s *TableDef) processTree() error {
+	s.TreeCodeColumn = nil
+	s.TreeParentColumn = nil
+	s.TreePathColumn = nil
+	s.TreeLevelColumn = nil
+	s.IsTreeParentInEdit = false
+	if s.TemplateCategory != "tree" {
+		if !g.IsEmpty(s.TreePathColumnName) || !g.IsEmpty(s.TreeLevelColumnName) {
+			return gerror.Newf("表 %s 不是树表，不能定义 treePathColumn/treeLevelColumn", s.Name)
+		}
+		return nil
+	}
+	findColumn := func(name string) *ColumnDef {
+		for _, column := range s.Columns {
+			if column.HtmlField == name || column.Name == name {
+				return column
+			}
+		}
+		return nil
+	}
+	s.TreeCodeColumn = findColumn(s.TreeCode)
+	if s.TreeCodeColumn == nil {
+		return gerror.Newf("树表 %s 的 treeCode 字段 %s 不存在于 columns 定义中", s.Name, s.TreeCode)
+	}
+	if s.PkColumn == nil || s.TreeCodeColumn != s.PkColumn {
+		return gerror.Newf("树表 %s 的 treeCode 字段 %s 必须为主键", s.Name, s.TreeCode)
+	}
+	s.TreeParentColumn = findColumn(s.TreeParentCode)
+	if s.TreeParentColumn == nil {
+		return gerror.Newf("树表 %s 的 treeParentCode 字段 %s 不存在于 columns 定义中", s.Name, s.TreeParentCode)
+	}
+	if s.TreeParentColumn.GoType != s.TreeCodeColumn.GoType {
+		return gerror.Newf("树表 %s 的 treeParentCode 字段与 treeCode 字段类型必须相同", s.Name)
+	}
+	if g.IsEmpty(s.TreePathColumnName) {
+		if !g.IsEmpty(s.TreeLevelColumnName) {
+			return gerror.Newf("树表 %s 的 treeLevelColumn 须与 treePathColumn 同时使用", s.Name)
+		}
+		return nil
+	}
+	pathColumn, found := s.ColumnMap[s.TreePathColumnName]
+	if !found {
+		return gerror.Newf("物化路径字段 %s 不存在于表 %s 的 columns 定义中", s.TreePathColumnName, s.Name)
+	}
+	if pathColumn.GoType != "string" {
+		return gerror.Newf("树表 %s 的物化路径字段 %s 必须为字符串类型", s.Name, s.TreePathColumnName)
+	}
+	s.TreePathColumn = pathColumn
+	if !g.IsEmpty(s.TreeLevelColumnName) {
+		levelColumn, found := s.ColumnMap[s.TreeLevelColumnName]
+		if !found {
+			return gerror.Newf("层级字段 %s 不存在于表 %s 的 columns 定义中", s.TreeLevelColumnName, s.Name)
+		}
+		if !IsIntegerGoType(levelColumn.GoType) {
+			return gerror.Newf("树表 %s 的层级字段 %s 必须为整数类型", s.Name, s.TreeLevelColumnName)
+		}
+		s.TreeLevelColumn = levelColumn
+	}
+	return nil
+}
+
+// IsTreePathColumn 是否树表的物化路径或层级字段，这两个字段由服务端根据父记录维护，不接受客户端输入
+func (s *TableDef) IsTreePathColumn(column *ColumnDef) bool {
+	if column == nil {
+		return false
+	}
+	return column == s.TreePathColumn || column == s.TreeLevelColumn
+}
+
+// IsAuditColumn 是否审计字段，审计字段由服务端根据 ctx 中的操作人及当前时间填充
+func (s *TableDef) IsAuditColumn(column *ColumnDef) bool {
+	if column == nil {
+		return false
+	}
+	return column == s.CreatedAtColumn || column == s.CreatedByColumn ||
+		column == s.UpdatedAtColumn || column == s.UpdatedByColumn
+}
+
 func (s *TableDef) ProcessColumns(ctx context.Context, yamlInputPath string, goModuleName string, cache map[string]*TableDef) (err error) {
 	for _, column := range s.Columns {
 		if g.IsEmpty(column.Name) {
@@ -224,6 +506,7 @@ func (s *TableDef) ProcessColumns(ctx context.Context, yamlInputPath string, goM
 		}
 		if column.IsPk {
 			s.PkColumns[column.Name] = column
+			s.PkColumn = column
 		}
 		if column.GoType == "Time" {
 			s.HasTimeColumnInMain = true
@@ -236,33 +519,103 @@ func (s *TableDef) ProcessColumns(ctx context.Context, yamlInputPath string, goM
 			s.HasCheckboxColumn = true
 		}
 	}
+	if !g.IsEmpty(s.VersionColumnName) {
+		versionColumn, found := s.ColumnMap[s.VersionColumnName]
+		if !found {
+			return gerror.Newf("乐观锁版本字段 %s 不存在于表 %s 的 columns 定义中", s.VersionColumnName, s.Name)
+		}
+		if !IsIntegerGoType(versionColumn.GoType) {
+			return gerror.Newf("表 %s 的乐观锁版本字段 %s 必须为整数类型", s.Name, s.VersionColumnName)
+		}
+		s.VersionColumn = versionColumn
+	}
+	switch s.Pagination {
+	case "", "page":
+	case "cursor":
+		if s.TemplateCategory == "tree" {
+			return gerror.Newf("表 %s 为树表，不支持游标分页", s.Name)
+		}
+		if s.PkColumn == nil {
+			return gerror.Newf("表 %s 没有主键，无法使用游标分页", s.Name)
+		}
+		sortColumn, found := s.ColumnMap[s.SortColumn]
+		if !found {
+			return gerror.Newf("表 %s 使用游标分页时，排序字段 %s 必须存在于 columns 定义中", s.Name, s.SortColumn)
+		}
+		sortType := gstr.ToLower(s.SortType)
+		if sortType != "asc" && sortType != "desc" {
+			return gerror.Newf("表 %s 使用游标分页时，排序方式只能为 asc 或 desc", s.Name)
+		}
+		s.SortType = sortType
+		s.SortColumnDef = sortColumn
+		s.IsCursorPagination = true
+	default:
+		return gerror.Newf("表 %s 的分页方式 %s 不正确，只能为 page 或 cursor", s.Name, s.Pagination)
+	}
+	switch s.RpcFramework {
+	case "", "dubbo":
+	case "grpc":
+		s.IsGrpc = true
+	default:
+		return gerror.Newf("表 %s 的 rpc 框架 %s 不正确，只能为 dubbo 或 grpc", s.Name, s.RpcFramework)
+	}
+	if s.RpcHttp && !s.IsRpc {
+		return gerror.Newf("表 %s 的 rpcHttp 只能在 isRpc 为 true 时使用", s.Name)
+	}
+	if err = s.processTree(); err != nil {
+		return err
+	}
 	for _, column := range s.VirtualColumns {
 		if err = column.SetColumnValues(); err != nil {
 			return err
 		}
 	}
 
+	// 审计字段（created_by/created_at/updated_by/updated_at）由服务端填充，不接受客户端输入
+	addColumns := make([]*AddColumnDef, 0, len(s.AddColumns))
 	for _, addColumn := range s.AddColumns {
 		columnName := addColumn.Name
 		baseColumn, found := s.ColumnMap[columnName]
 		if !found {
 			return gerror.Newf("新增字段 %s 不存在于表 %s 的 columns 定义中", s.Name, columnName)
 		}
+		if s.IsAuditColumn(baseColumn) || s.IsTreePathColumn(baseColumn) {
+			continue
+		}
 		s.SetAddColumnValues(addColumn, baseColumn)
+		if baseColumn.IsPk {
+			s.IsPkInAdd = true
+		}
+		addColumns = append(addColumns, addColumn)
 	}
+	s.AddColumns = addColumns
 	isPkInEdit := false
+	isVersionInEdit := false
+	editColumns := make([]*EditColumnDef, 0, len(s.EditColumns))
 	for _, editColumn := range s.EditColumns {
 		columnName := editColumn.Name
 		baseColumn, found := s.ColumnMap[columnName]
 		if !found {
 			return gerror.Newf("编辑字段 %s 不存在于表 %s 的 columns 定义中", s.Name, columnName)
 		}
+		if s.IsAuditColumn(baseColumn) || s.IsTreePathColumn(baseColumn) {
+			continue
+		}
+		if s.TreeParentColumn != nil && baseColumn == s.TreeParentColumn {
+			s.IsTreeParentInEdit = true
+		}
+		editColumns = append(editColumns, editColumn)
 		if baseColumn.IsPk {
 			isPkInEdit = true
 		}
+		if baseColumn == s.VersionColumn {
+			isVersionInEdit = true
+		}
 		s.SetEditColumnValues(editColumn, baseColumn)
 	}
+	s.EditColumns = editColumns
 	s.IsPkInEdit = isPkInEdit
+	s.IsVersionInEdit = isVersionInEdit
 	for _, listColumn := range s.ListColumns {
 		columnName := listColumn.Name
 		baseColumn, found := s.ColumnMap[columnName]
@@ -272,6 +625,9 @@ func (s *TableDef) ProcessColumns(ctx context.Context, yamlInputPath string, goM
 				return gerror.Newf("列表字段 %s 不存在于表 %s 的 columns 和 virtualColumns 定义中", s.Name, columnName)
 			}
 		}
+		if listColumn.IsSortable && baseColumn.IsVirtual {
+			return gerror.Newf("列表字段 %s 为虚拟字段，不能设置 sortable", columnName)
+		}
 		s.SetListColumnValues(listColumn, baseColumn)
 	}
 	for _, detailColumn := range s.DetailColumns {
@@ -294,7 +650,10 @@ func (s *TableDef) ProcessColumns(ctx context.Context, yamlInputPath string, goM
 				return gerror.Newf("查询字段 %s 不存在于表 %s 的 columns 和 virtualColumns 定义中", s.Name, columnName)
 			}
 		}
-		hasConversion := s.SetQueryColumnValues(queryColumn, baseColumn)
+		hasConversion, err1 := s.SetQueryColumnValues(queryColumn, baseColumn)
+		if err1 != nil {
+			return err1
+		}
 		s.HasConversion = s.HasConversion || hasConversion
 		if baseColumn.IsVirtual {
 			s.HasVirtualQueries = true
@@ -306,7 +665,393 @@ func (s *TableDef) ProcessColumns(ctx context.Context, yamlInputPath string, goM
 			s.VirtualQueryRelated[foreignTableName] = foreignTable
 		}
 	}
-	return
+	if err = s.processAggregations(); err != nil {
+		return err
+	}
+	if err = s.processCache(); err != nil {
+		return err
+	}
+	return s.ProcessKeywordSearch(ctx, yamlInputPath, goModuleName, cache)
+}
+
+// processAggregations 检查统计聚合定义，分组字段及统计字段只能是本表字段
+// 分组名与指标名共同组成返回结果中每一行的字段，不能重复
+func (s *TableDef) processAggregations() error {
+	aggregations := s.Aggregations
+	if aggregations == nil {
+		return nil
+	}
+	if len(aggregations.GroupBy) == 0 || len(aggregations.Metrics) == 0 {
+		return gerror.Newf("表 %s 的统计聚合定义必须给定 groupBy 和 metrics", s.Name)
+	}
+	names := gset.NewStrSet()
+	for _, group := range aggregations.GroupBy {
+		column, found := s.ColumnMap[group.Column]
+		if !found {
+			return gerror.Newf("统计分组字段 %s 不存在于表 %s 的 columns 定义中", group.Column, s.Name)
+		}
+		group.Base = column
+		group.IsDate = column.GoType == "Time"
+		if group.IsDate {
+			group.DateBucket = gstr.ToLower(group.DateBucket)
+			if group.DateBucket == "" {
+				group.DateBucket = "day"
+			}
+			if !IsExistInArray(group.DateBucket, []string{"day", "week", "month", "year"}) {
+				return gerror.Newf("表 %s 的统计分组字段 %s 的分桶方式 %s 不正确，只能为 day、week、month 或 year", s.Name, group.Column, group.DateBucket)
+			}
+			group.GoType = "string"
+			group.ProtoType = "string"
+		} else {
+			if !g.IsEmpty(group.DateBucket) {
+				return gerror.Newf("表 %s 的统计分组字段 %s 不是时间字段，不能指定 dateBucket", s.Name, group.Column)
+			}
+			group.GoType = column.GoType
+			group.ProtoType = column.ProtoType
+		}
+		if g.IsEmpty(group.Name) {
+			group.Name = column.HtmlField
+		}
+		if g.IsEmpty(group.Comment) {
+			group.Comment = column.Comment
+		}
+		group.GoField = gstr.CaseCamel(group.Name)
+		group.HtmlField = gstr.CaseCamelLower(group.Name)
+		if names.Contains(group.HtmlField) {
+			return gerror.Newf("表 %s 的统计分组及指标名 %s 重复", s.Name, group.HtmlField)
+		}
+		names.Add(group.HtmlField)
+	}
+	funcComments := map[string]string{"count": "数量", "sum": "合计", "avg": "平均", "min": "最小", "max": "最大"}
+	for _, metric := range aggregations.Metrics {
+		metric.Func = gstr.ToLower(metric.Func)
+		funcComment, found := funcComments[metric.Func]
+		if !found {
+			return gerror.Newf("表 %s 的统计指标聚合函数 %s 不正确，只能为 count、sum、avg、min 或 max", s.Name, metric.Func)
+		}
+		metric.SqlFunc = gstr.ToUpper(metric.Func)
+		metric.Base = nil
+		if g.IsEmpty(metric.Column) {
+			if metric.Func != "count" {
+				return gerror.Newf("表 %s 的统计指标 %s 必须给定 column", s.Name, metric.Func)
+			}
+			if g.IsEmpty(metric.Name) {
+				metric.Name = "count"
+			}
+			if g.IsEmpty(metric.Comment) {
+				metric.Comment = "记录数"
+			}
+		} else {
+			column, found := s.ColumnMap[metric.Column]
+			if !found {
+				return gerror.Newf("统计指标字段 %s 不存在于表 %s 的 columns 定义中", metric.Column, s.Name)
+			}
+			if metric.Func != "count" && !IsIntegerGoType(column.GoType) && column.GoType != "float64" {
+				return gerror.Newf("表 %s 的统计指标字段 %s 必须为数值类型", s.Name, metric.Column)
+			}
+			metric.Base = column
+			if g.IsEmpty(metric.Name) {
+				metric.Name = metric.Func + column.GoField
+			}
+			if g.IsEmpty(metric.Comment) {
+				metric.Comment = column.Comment + funcComment
+			}
+		}
+		if metric.Func == "count" {
+			metric.GoType = "int64"
+			metric.ProtoType = "int64"
+		} else {
+			metric.GoType = "float64"
+			metric.ProtoType = "double"
+		}
+		metric.GoField = gstr.CaseCamel(metric.Name)
+		metric.HtmlField = gstr.CaseCamelLower(metric.Name)
+		if names.Contains(metric.HtmlField) {
+			return gerror.Newf("表 %s 的统计分组及指标名 %s 重复", s.Name, metric.HtmlField)
+		}
+		names.Add(metric.HtmlField)
+	}
+	return nil
+}
+
+// processCache 检查智能缓存策略定义，合并表级缺省值后得到各读方法的缓存策略
+func (s *TableDef) processCache() error {
+	methods := []string{"GetList", "GetInfoById", "DoGetList", "DoGetOne"}
+	if s.Aggregations != nil {
+		methods = append(methods, "Aggregate")
+	}
+	if s.Cache == nil {
+		s.Cache = &CacheDef{}
+	}
+	cacheDef := s.Cache
+	for name := range cacheDef.Methods {
+		if !IsExistInArray(name, methods) {
+			return gerror.Newf("表 %s 的缓存策略方法 %s 不正确，只能为 %s", s.Name, name, gstr.Join(methods, "、"))
+		}
+	}
+	cacheDef.Policies = make(map[string]*CacheMethodDef, len(methods))
+	for _, name := range methods {
+		policy := &CacheMethodDef{
+			TTL:       cacheDef.TTL,
+			Scope:     cacheDef.Scope,
+			Downgrade: cacheDef.Downgrade,
+		}
+		if methodDef, found := cacheDef.Methods[name]; found && methodDef != nil {
+			policy.Disabled = methodDef.Disabled
+			policy.Fallback = methodDef.Fallback
+			if !g.IsEmpty(methodDef.TTL) {
+				policy.TTL = methodDef.TTL
+			}
+			if !g.IsEmpty(methodDef.Scope) {
+				policy.Scope = methodDef.Scope
+			}
+			if !g.IsEmpty(methodDef.Downgrade) {
+				policy.Downgrade = methodDef.Downgrade
+			}
+		}
+		if policy.Scope == "none" {
+			policy.Scope = ""
+		}
+		if !g.IsEmpty(policy.TTL) {
+			ttl, err := time.ParseDuration(policy.TTL)
+			if err != nil || ttl < 0 {
+				return gerror.Newf("表 %s 的方法 %s 的缓存有效期 %s 不正确，应为 30s、5m、1h 这样的时长", s.Name, name, policy.TTL)
+			}
+			policy.TtlMillis = ttl.Milliseconds()
+		}
+		policy.Downgrade = gstr.ToLower(policy.Downgrade)
+		if policy.Downgrade == "" {
+			policy.Downgrade = "fallback"
+		}
+		if !IsExistInArray(policy.Downgrade, []string{"stale", "error", "fallback"}) {
+			return gerror.Newf("表 %s 的方法 %s 的缓存降级策略 %s 不正确，只能为 stale、error 或 fallback", s.Name, name, policy.Downgrade)
+		}
+		if !g.IsEmpty(policy.Fallback) {
+			if policy.Downgrade != "fallback" {
+				return gerror.Newf("表 %s 的方法 %s 指定了 fallback，降级策略只能为 fallback", s.Name, name)
+			}
+			if !gjson.Valid(policy.Fallback) {
+				return gerror.Newf("表 %s 的方法 %s 的 fallback 不是合法的 JSON", s.Name, name)
+			}
+		}
+		cacheDef.Policies[name] = policy
+	}
+	return nil
+}
+
+// ProcessKeywordSearch 解析关键字搜索的字段，虚拟字段对应的关联表加入 VirtualQueryRelated 以便通过子查询匹配
+func (s *TableDef) ProcessKeywordSearch(ctx context.Context, yamlInputPath string, goModuleName string, cache map[string]*TableDef) error {
+	keywordSearch := s.KeywordSearch
+	if keywordSearch == nil {
+		return nil
+	}
+	if len(keywordSearch.Columns) == 0 {
+		return gerror.Newf("表 %s 的关键字搜索没有给定 columns", s.Name)
+	}
+	keywordSearch.Mode = gstr.ToLower(keywordSearch.Mode)
+	if keywordSearch.Mode == "" {
+		keywordSearch.Mode = "like"
+	}
+	if keywordSearch.Mode != "like" && keywordSearch.Mode != "fulltext" {
+		return gerror.Newf("表 %s 的关键字搜索方式 %s 不正确，只能为 like 或 fulltext", s.Name, keywordSearch.Mode)
+	}
+	keywordSearch.RealColumns = nil
+	keywordSearch.VirtualColumns = nil
+	comments := make([]string, 0, len(keywordSearch.Columns))
+	for _, columnName := range keywordSearch.Columns {
+		column, found := s.ColumnMap[columnName]
+		if found {
+			keywordSearch.RealColumns = append(keywordSearch.RealColumns, column)
+			comments = append(comments, column.Comment)
+			continue
+		}
+		column, found = s.VirtualColumnMap[columnName]
+		if !found {
+			return gerror.Newf("关键字搜索字段 %s 不存在于表 %s 的 columns 和 virtualColumns 定义中", columnName, s.Name)
+		}
+		foreignTable, err := LoadTableDefYaml(ctx, column.ForeignTableName, yamlInputPath, goModuleName, cache)
+		if err != nil {
+			return err
+		}
+		s.VirtualQueryRelated[column.ForeignTableName] = foreignTable
+		keywordSearch.VirtualColumns = append(keywordSearch.VirtualColumns, column)
+		comments = append(comments, column.Comment)
+	}
+	if keywordSearch.Mode == "fulltext" && len(keywordSearch.RealColumns) == 0 {
+		return gerror.Newf("表 %s 的关键字搜索方式为 fulltext 时，至少需要一个本表字段", s.Name)
+	}
+	keywordSearch.Comments = gstr.Join(comments, "/")
+	return nil
+}
+
+// ProcessChildren 加载主从表中的子表定义
+// 子表记录在主表新增/修改时整体提交：主键为空的插入，主键不为空的更新，修改时不在提交列表中的原有子表记录被删除
+func (s *TableDef) ProcessChildren(ctx context.Context, yamlInputPath string, goModuleName string, cache map[string]*TableDef) error {
+	if len(s.Children) == 0 {
+		return nil
+	}
+	if s.PkColumn == nil {
+		return gerror.Newf("表 %s 没有主键，无法定义子表", s.Name)
+	}
+	if !s.PkColumn.IsIncrement && !s.IsPkInAdd {
+		return gerror.Newf("表 %s 的主键既不是自增长字段也不在新增字段中，无法关联子表记录", s.Name)
+	}
+	for _, child := range s.Children {
+		if g.IsEmpty(child.TableName) || g.IsEmpty(child.ForeignKeyColumnName) {
+			return gerror.Newf("表 %s 的子表定义必须给定 tableName 和 foreignKeyColumnName", s.Name)
+		}
+		if child.TableName == s.Name {
+			return gerror.Newf("表 %s 不能将自身定义为子表，树形结构请使用 tree 类型", s.Name)
+		}
+		childTable, err := LoadTableDefYaml(ctx, child.TableName, yamlInputPath, goModuleName, cache)
+		if err != nil {
+			return err
+		}
+		fkColumn, found := childTable.ColumnMap[child.ForeignKeyColumnName]
+		if !found {
+			return gerror.Newf("外键字段 %s 不存在于子表 %s 的 columns 定义中", child.ForeignKeyColumnName, child.TableName)
+		}
+		if childTable.PkColumn == nil || !childTable.PkColumn.IsIncrement {
+			return gerror.Newf("子表 %s 必须有自增长主键", child.TableName)
+		}
+		child.Table = childTable
+		child.FkColumn = fkColumn
+		child.ClassName = s.ClassName + childTable.ClassName
+		if g.IsEmpty(child.Name) {
+			child.Name = gstr.CaseCamelLower(childTable.BusinessName) + "List"
+		}
+		child.GoField = gstr.CaseCamel(child.Name)
+		child.HtmlField = gstr.CaseCamelLower(child.Name)
+		if g.IsEmpty(child.Comment) {
+			child.Comment = childTable.FunctionName
+		}
+		child.EditColumns = nil
+		for _, editColumn := range childTable.EditColumns {
+			if editColumn.Base.IsPk || editColumn.Base == fkColumn {
+				continue
+			}
+			child.EditColumns = append(child.EditColumns, editColumn)
+		}
+		s.HasTimeColumn = s.HasTimeColumn || childTable.HasTimeColumnInMain
+	}
+	s.addPkToItem()
+	return nil
+}
+
+// addPkToItem 列表查询时需要主键才能加载子表及多对多关联记录，主键不在列表返回结果中时加入 FkColumnsNotInList
+// 树表的 treeCode 字段已在列表返回结果中
+func (s *TableDef) addPkToItem() {
+	if s.IsInList(s.PkColumn.Name) || (s.TemplateCategory == "tree" && s.PkColumn.HtmlField == s.TreeCode) {
+		return
+	}
+	for _, column := range s.FkColumnsNotInList {
+		if column == s.PkColumn {
+			return
+		}
+	}
+	s.FkColumnsNotInList = append(s.FkColumnsNotInList, s.PkColumn)
+}
+
+// ProcessManyToMany 加载多对多关联的远端表，并根据关联表的两个外键字段生成关联表定义
+// 关联表的 entity/dao 生成在当前表的 package 下，远端表通过其 service 的 GetPkReference 查询
+func (s *TableDef) ProcessManyToMany(ctx context.Context, yamlInputPath string, goModuleName string, cache map[string]*TableDef) error {
+	if len(s.ManyToMany) == 0 {
+		return nil
+	}
+	if s.PkColumn == nil {
+		return gerror.Newf("表 %s 没有主键，无法定义多对多关联", s.Name)
+	}
+	if !s.PkColumn.IsIncrement && !s.IsPkInAdd {
+		return gerror.Newf("表 %s 的主键既不是自增长字段也不在新增字段中，无法同步多对多关联表", s.Name)
+	}
+	s.ManyToManyRemotes = nil
+	s.ManyToManyListTables = nil
+	for _, m := range s.ManyToMany {
+		if g.IsEmpty(m.JoinTableName) || g.IsEmpty(m.LocalKeyColumnName) || g.IsEmpty(m.RemoteKeyColumnName) ||
+			g.IsEmpty(m.RemoteTableName) || g.IsEmpty(m.RemoteValueColumnName) {
+			return gerror.Newf("表 %s 的多对多关联定义必须给定 joinTableName、localKeyColumnName、remoteKeyColumnName、remoteTableName 和 remoteValueColumnName", s.Name)
+		}
+		if m.LocalKeyColumnName == m.RemoteKeyColumnName {
+			return gerror.Newf("多对多关联表 %s 的 localKeyColumnName 与 remoteKeyColumnName 不能相同", m.JoinTableName)
+		}
+		remoteTable, err := LoadTableDefYaml(ctx, m.RemoteTableName, yamlInputPath, goModuleName, cache)
+		if err != nil {
+			return err
+		}
+		if remoteTable.PkColumn == nil {
+			return gerror.Newf("多对多关联的远端表 %s 没有主键", m.RemoteTableName)
+		}
+		remoteValueColumn, found := remoteTable.ColumnMap[m.RemoteValueColumnName]
+		if !found {
+			return gerror.Newf("显示字段 %s 不存在于远端表 %s 的 columns 定义中", m.RemoteValueColumnName, m.RemoteTableName)
+		}
+		joinTable := &TableDef{
+			Name:             m.JoinTableName,
+			Comment:          s.FunctionName + "与" + remoteTable.FunctionName + "关联表",
+			BackendPackage:   s.BackendPackage,
+			FrontendModule:   s.FrontendModule,
+			TemplateCategory: "crud",
+			BusinessName:     s.BusinessName + "_" + remoteTable.BusinessName,
+			FunctionName:     s.FunctionName + "与" + remoteTable.FunctionName + "关联",
+			FunctionAuthor:   s.FunctionAuthor,
+			CreateTime:       s.CreateTime,
+			UpdateTime:       s.UpdateTime,
+			PkColumns:        make(map[string]*ColumnDef),
+			ColumnMap:        make(map[string]*ColumnDef),
+		}
+		joinTable.SetVariableNames(goModuleName)
+		joinTable.Columns = []*ColumnDef{
+			{Name: m.LocalKeyColumnName, Comment: s.FunctionName, SqlType: s.PkColumn.SqlType, IsRequired: true},
+			{Name: m.RemoteKeyColumnName, Comment: remoteTable.FunctionName, SqlType: remoteTable.PkColumn.SqlType, IsRequired: true},
+		}
+		for i, column := range joinTable.Columns {
+			column.Sort = i + 1
+			if err = column.SetColumnValues(); err != nil {
+				return err
+			}
+			joinTable.ColumnMap[column.Name] = column
+		}
+		m.JoinTable = joinTable
+		m.LocalKeyColumn = joinTable.Columns[0]
+		m.RemoteKeyColumn = joinTable.Columns[1]
+		m.RemoteTable = remoteTable
+		m.RemoteValueColumn = remoteValueColumn
+		m.ClassName = s.ClassName + remoteTable.ClassName
+		if g.IsEmpty(m.Name) {
+			m.Name = gstr.CaseCamelLower(remoteTable.BusinessName) + "List"
+		}
+		if g.IsEmpty(m.IdsName) {
+			m.IdsName = gstr.CaseCamelLower(remoteTable.BusinessName) + "Ids"
+		}
+		m.GoField = gstr.CaseCamel(m.Name)
+		m.HtmlField = gstr.CaseCamelLower(m.Name)
+		m.IdsGoField = gstr.CaseCamel(m.IdsName)
+		m.IdsHtmlField = gstr.CaseCamelLower(m.IdsName)
+		if g.IsEmpty(m.Comment) {
+			m.Comment = remoteTable.FunctionName
+		}
+
+		found = false
+		for _, t := range s.ManyToManyRemotes {
+			if t == remoteTable {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		s.ManyToManyRemotes = append(s.ManyToManyRemotes, remoteTable)
+		if remoteTable.Name == s.Name {
+			continue
+		}
+		if s.AllRelatedTableMap != nil && s.AllRelatedTableMap.Contains(remoteTable.ClassName) {
+			continue
+		}
+		s.ManyToManyListTables = append(s.ManyToManyListTables, remoteTable)
+	}
+	s.addPkToItem()
+	return nil
 }
 
 func (c *ColumnDef) SetColumnValues() error {
@@ -415,7 +1160,7 @@ func (s *TableDef) SetEditColumnValues(editColumn *EditColumnDef, baseColumn *Co
 	if g.IsEmpty(editColumn.HtmlType) {
 		editColumn.HtmlType = baseColumn.HtmlType
 	}
-	if baseColumn.IsPk {
+	if baseColumn.IsPk || baseColumn == s.VersionColumn {
 		editColumn.IsDisabled = true
 	}
 }
@@ -431,7 +1176,7 @@ func (s *TableDef) SetListColumnValues(listColumn *ListColumnDef, baseColumn *Co
 	}
 }
 
-func (s *TableDef) SetQueryColumnValues(queryColumn *QueryColumnDef, baseColumn *ColumnDef) (hasConversion bool) {
+func (s *TableDef) SetQueryColumnValues(queryColumn *QueryColumnDef, baseColumn *ColumnDef) (hasConversion bool, err error) {
 	columnName := baseColumn.Name
 	queryColumn.Base = baseColumn
 	queryColumn.Comment = baseColumn.Comment
@@ -441,63 +1186,102 @@ func (s *TableDef) SetQueryColumnValues(queryColumn *QueryColumnDef, baseColumn
 	if g.IsEmpty(queryColumn.HtmlType) {
 		queryColumn.HtmlType = baseColumn.HtmlType
 	}
+	// 查询类型统一为大写，多个单词以一个空格分隔，如 not_in 转为 NOT IN
+	queryColumn.QueryType = gstr.Join(gstr.SplitAndTrim(gstr.ToUpper(gstr.Replace(queryColumn.QueryType, "_", " ")), " "), " ")
 	if g.IsEmpty(queryColumn.QueryType) {
 		queryColumn.QueryType = "EQ"
 	}
+	if !IsExistInArray(queryColumn.QueryType, QueryTypes) {
+		return false, gerror.Newf("查询字段 %s 的查询类型 %s 不正确", columnName, queryColumn.QueryType)
+	}
+	if queryColumn.IsIgnoreCase && (baseColumn.GoType != "string" ||
+		!IsExistInArray(queryColumn.QueryType, []string{"EQ", "NE", "LIKE", "PREFIX", "SUFFIX"})) {
+		return false, gerror.Newf("查询字段 %s 只有字符串类型的 EQ|NE|LIKE|PREFIX|SUFFIX 查询才能设置 ignoreCase", columnName)
+	}
+	queryColumn.IsArrayQuery = IsExistInArray(queryColumn.QueryType, []string{"BETWEEN", "IN", "NOT IN"})
+	queryColumn.IsNullQuery = IsExistInArray(queryColumn.QueryType, []string{"IS NULL", "IS NOT NULL"})
+	switch {
+	case queryColumn.IsNullQuery:
+		queryColumn.ReqGoType = "bool"
+		queryColumn.ReqProtoType = "bool"
+	case IsExistInArray(baseColumn.GoType, []string{"Time", "int", "int64", "uint", "uint64", "float", "float64", "bool"}):
+		queryColumn.ReqGoType = "string"
+		queryColumn.ReqProtoType = "string"
+	default:
+		queryColumn.ReqGoType = baseColumn.GoType
+		queryColumn.ReqProtoType = baseColumn.ProtoType
+	}
+	if queryColumn.IsArrayQuery {
+		queryColumn.ReqGoType = "[]" + queryColumn.ReqGoType
+		queryColumn.ReqProtoType = "repeated " + queryColumn.ReqProtoType
+	}
+	if queryColumn.IsNullQuery {
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@boolean#" + baseColumn.Comment + "需为true/false"
+		return
+	}
 	// validation 规则 和 conversion 方法
 	integerValidationRule := "integer"
 	floatValidationRule := "float"
+	booleanValidationRule := "boolean"
 	dateValidationRule := "date"
 	datetimeValidationRule := "date-format:Y-m-d H:i:s"
-	if queryColumn.QueryType == "BETWEEN" {
-		integerValidationRule += "-array"
-		floatValidationRule += "-array"
-		dateValidationRule += "-array"
-		datetimeValidationRule += "-array"
+	if queryColumn.IsArrayQuery {
+		// 数组参数使用 foreach 逐个元素校验，foreach 本身不占用错误提示，因此提示信息前加一个空位
+		integerValidationRule = "foreach|" + integerValidationRule + "#|"
+		floatValidationRule = "foreach|" + floatValidationRule + "#|"
+		booleanValidationRule = "foreach|" + booleanValidationRule + "#|"
+		dateValidationRule = "foreach|" + dateValidationRule + "#|"
+		datetimeValidationRule = "foreach|" + datetimeValidationRule + "#|"
+	} else {
+		integerValidationRule += "#"
+		floatValidationRule += "#"
+		booleanValidationRule += "#"
+		dateValidationRule += "#"
+		datetimeValidationRule += "#"
 	}
 	hasConversion = false
 	switch baseColumn.GoType {
 	case "int":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + "#" + baseColumn.Comment + "需为整数"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + baseColumn.Comment + "需为整数"
 		queryColumn.FieldConversion = "gconv.Int"
 		hasConversion = true
 		break
 	case "int64":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + "#" + baseColumn.Comment + "需为整数"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + baseColumn.Comment + "需为整数"
 		queryColumn.FieldConversion = "gconv.Int64"
 		hasConversion = true
 		break
 	case "uint":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + "#" + baseColumn.Comment + "需为整数"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + baseColumn.Comment + "需为整数"
 		queryColumn.FieldConversion = "gconv.Uint"
 		hasConversion = true
 		break
 	case "uint64":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + "#" + baseColumn.Comment + "需为整数"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + integerValidationRule + baseColumn.Comment + "需为整数"
 		queryColumn.FieldConversion = "gconv.Uint64"
 		hasConversion = true
 		break
 	case "float":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + floatValidationRule + "#" + baseColumn.Comment + "需为浮点数"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + floatValidationRule + baseColumn.Comment + "需为浮点数"
 		queryColumn.FieldConversion = "gconv.Float"
 		hasConversion = true
 		break
 	case "float64":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + floatValidationRule + "#" + baseColumn.Comment + "需为浮点数"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + floatValidationRule + baseColumn.Comment + "需为浮点数"
 		queryColumn.FieldConversion = "gconv.Float64"
 		hasConversion = true
 		break
 	case "bool":
-		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@boolean#" + baseColumn.Comment + "需为true/false"
+		queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + booleanValidationRule + baseColumn.Comment + "需为true/false"
 		queryColumn.FieldConversion = "gconv.Bool"
 		hasConversion = true
 		break
 	case "Time":
 		if baseColumn.HtmlType == "date" {
-			queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + dateValidationRule + "#" + baseColumn.Comment + "需为YYYY-MM-DD格式"
+			queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + dateValidationRule + baseColumn.Comment + "需为YYYY-MM-DD格式"
 			queryColumn.FieldConversion = "gconv.Time"
 		} else {
-			queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + datetimeValidationRule + "#" + baseColumn.Comment + "需为YYYY-MM-DD hh:mm:ss格式"
+			queryColumn.FieldValidation = gstr.CaseCamelLower(columnName) + "@" + datetimeValidationRule + baseColumn.Comment + "需为YYYY-MM-DD hh:mm:ss格式"
 			queryColumn.FieldConversion = "gconv.Time"
 		}
 		hasConversion = true
@@ -635,6 +1419,25 @@ func (s *TableDef) ProcessRelatedAndForeign(ctx context.Context, yamlInputPath s
 		s.AllRelatedTables = s.AllRelatedTableMap.Values()
 	}
 
+	if s.Import {
+		for _, addColumn := range s.AddColumns {
+			relatedTable := addColumn.Base.RelatedTable
+			if relatedTable == nil || relatedTable.ClassName == s.ClassName {
+				continue
+			}
+			found := false
+			for _, t := range s.ImportRelatedTables {
+				if t == relatedTable {
+					found = true
+					break
+				}
+			}
+			if !found {
+				s.ImportRelatedTables = append(s.ImportRelatedTables, relatedTable)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -700,6 +1503,7 @@ func (s *TableDef) ProcessColumnRelatedAndForeign(ctx context.Context, column *C
 		column.CombinedTableClass = relatedTable.ClassNameWhenRelated
 		column.CombinedHtmlTableClass = relatedTable.ClassName
 		column.CombinedHtmlField = relatedTable.JsonNameWhenRelated + "." + gstr.CaseCamelLower(column.RelatedValueColumnName)
+		column.RelatedTable = relatedTable
 
 		if s.AllRelatedTableMap == nil {
 			s.AllRelatedTableMap = gmap.NewListMap()
@@ -764,3 +1568,314 @@ func (s *TableDef) IsInList(columnName string) bool {
 	}
 	return false
 }
+
+func (s *TableDef) IsInAdd(columnName string) bool {
+	for _, c := range s.AddColumns {
+		if c.Name == columnName {
+			return true
+		}
+	}
+	return false
+}
+
+// TestTables 生成的 service 单元测试中需要建表的表（按表名去重）：当前表、历史表、子表、多对多关联表及远端表、关联表
+func (s *TableDef) TestTables() []*TableDef {
+	var (
+		tables []*TableDef
+		names  = gset.NewStrSet()
+	)
+	add := func(t *TableDef) {
+		if t == nil || !names.AddIfNotExist(t.Name) {
+			return
+		}
+		tables = append(tables, t)
+	}
+	add(s)
+	add(s.HistoryTable)
+	for _, child := range s.Children {
+		add(child.Table)
+	}
+	for _, m := range s.ManyToMany {
+		add(m.JoinTable)
+		add(m.RemoteTable)
+	}
+	for _, relatedTable := range s.AllRelatedTables {
+		add(relatedTable.(*TableDef))
+	}
+	for _, relatedTable := range s.VirtualQueryRelated {
+		add(relatedTable)
+	}
+	return tables
+}
+
+// CacheDependencies 查询结果中嵌入了数据的其他表的 ClassName（去重并排序）：关联表、虚拟字段查询的关联表、子表及多对多远端表
+// 这些表的数据变更时，当前表的缓存须一并清除
+func (s *TableDef) CacheDependencies() []string {
+	names := gset.NewStrSet()
+	add := func(t *TableDef) {
+		if t != nil && t.ClassName != s.ClassName {
+			names.Add(t.ClassName)
+		}
+	}
+	for _, relatedTable := range s.AllRelatedTables {
+		add(relatedTable.(*TableDef))
+	}
+	for _, relatedTable := range s.VirtualQueryRelated {
+		add(relatedTable)
+	}
+	for _, child := range s.Children {
+		add(child.Table)
+	}
+	for _, m := range s.ManyToMany {
+		add(m.RemoteTable)
+	}
+	dependencies := names.Slice()
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// HttpRoutePrefix http 路由分组的路径，与 router 中注册的路由一致，如 /app/demo/shop/order-item
+func (s *TableDef) HttpRoutePrefix() string {
+	prefix := "/"
+	if gstr.ContainsI(s.BackendPackage, "plugins") {
+		prefix += "plugins/"
+	}
+	return prefix + s.PackageName + "/" + s.RouteChildPath
+}
+
+// HasTestValue 生成的 service 单元测试中新增/修改时是否为该字段赋示例值
+// 自增主键、文件字段、树表的父节点字段（测试记录均为根节点）及乐观锁版本字段不赋值
+func (s *TableDef) HasTestValue(column *ColumnDef) bool {
+	if column.IsIncrement || column.IsVirtual {
+		return false
+	}
+	if column.HtmlType == "images" || column.HtmlType == "file" || column.HtmlType == "files" {
+		return false
+	}
+	if s.TreeParentColumn != nil && column.Name == s.TreeParentColumn.Name {
+		return false
+	}
+	return s.VersionColumn == nil || column.Name != s.VersionColumn.Name
+}
+
+// TestQueryExpect 生成的 service 单元测试中，以 TestQueryValue 为查询条件时应查到的记录数，-1 表示无法确定记录数，不生成该查询的测试
+// 测试记录的各字段取第 2 个示例值，自增主键为 1（每个测试开始前重置自增序列），
+// 时间戳字段为当前时间（晚于所有示例值），乐观锁版本号为 0，其余未在新增时赋值的字段为 NULL；
+// 虚拟字段取测试中预先插入的关联表记录的第 2 个示例值（见 TestForeignRows）
+func (s *TableDef) TestQueryExpect(queryColumn *QueryColumnDef) int {
+	value, isNull, known := s.testRecordValue(queryColumn.Base)
+	if !known {
+		return -1
+	}
+	switch queryColumn.QueryType {
+	case "IS NULL":
+		return boolToCount(isNull)
+	case "IS NOT NULL":
+		return boolToCount(!isNull)
+	}
+	if isNull {
+		// 与 NULL 比较的结果均不成立
+		return 0
+	}
+	// bool 类型的示例值 true/false 交替，按 SQL 中的 1/0 比较
+	n := func(i int) int {
+		if queryColumn.Base.GoType == "bool" {
+			return i % 2
+		}
+		return i
+	}
+	if queryColumn.Base.GoType == "bool" {
+		value = value % 2
+	}
+	switch queryColumn.QueryType {
+	case "NE", "NOT IN":
+		return boolToCount(value != n(1))
+	case "GT":
+		return boolToCount(value > n(1))
+	case "GTE":
+		return boolToCount(value >= n(2))
+	case "LT":
+		return boolToCount(value < n(3))
+	case "LTE":
+		return boolToCount(value <= n(2))
+	case "BETWEEN":
+		return boolToCount(value >= n(1) && value < n(3))
+	default:
+		return boolToCount(value == n(2))
+	}
+}
+
+// testRecordValue 测试记录中该字段的值为第几个示例值，时间戳字段的当前时间记为 100
+func (s *TableDef) testRecordValue(column *ColumnDef) (value int, isNull bool, known bool) {
+	switch {
+	case column.IsVirtual:
+		// 关联表为当前表时，预先插入的记录会影响查询结果
+		fkColumn, found := s.ColumnMap[column.ForeignKeyColumnName]
+		if !found || column.ForeignTableName == s.Name || !s.IsInAdd(fkColumn.Name) || !s.HasTestValue(fkColumn) {
+			return 0, false, false
+		}
+		return 2, false, true
+	case column.IsPk && column.IsIncrement:
+		return 1, false, true
+	case s.IsInAdd(column.Name):
+		if !s.HasTestValue(column) {
+			return 0, false, false
+		}
+		return 2, false, true
+	case column == s.CreatedAtColumn, column == s.UpdatedAtColumn,
+		column.Name == "created_at", column.Name == "updated_at":
+		// gf ORM 新增记录时自动填充 created_at/updated_at
+		return 100, false, true
+	case s.VersionColumn != nil && column.Name == s.VersionColumn.Name:
+		return 0, false, true
+	case s.IsTreePathColumn(column):
+		return 0, false, false
+	default:
+		// 包括 ctx 中没有操作人时的 created_by/updated_by
+		return 0, true, true
+	}
+}
+
+func boolToCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// TestForeignRow 生成的 service 单元测试中为虚拟字段查询预先插入的关联表记录
+type TestForeignRow struct {
+	Table  string            // 关联表名
+	Values map[string]string // 字段名及其值的 Go 表达式
+}
+
+// TestForeignRows 虚拟查询字段对应的关联表记录：主键为测试记录中外键的第 2 个示例值，虚拟字段对应的值取第 2 个示例值
+func (s *TableDef) TestForeignRows() []*TestForeignRow {
+	var rows []*TestForeignRow
+	rowMap := map[string]*TestForeignRow{}
+	for _, queryColumn := range s.QueryColumns {
+		column := queryColumn.Base
+		if !column.IsVirtual {
+			continue
+		}
+		if _, _, known := s.testRecordValue(column); !known {
+			continue
+		}
+		foreignTable, found := s.VirtualQueryRelated[column.ForeignTableName]
+		if !found || foreignTable.PkColumn == nil {
+			continue
+		}
+		fkColumn := s.ColumnMap[column.ForeignKeyColumnName]
+		// 外键均取第 2 个示例值，同一关联表只插入一条记录
+		row, found := rowMap[foreignTable.Name]
+		if !found {
+			row = &TestForeignRow{
+				Table:  foreignTable.Name,
+				Values: map[string]string{foreignTable.PkColumn.Name: fkColumn.TestValue(2)},
+			}
+			rowMap[foreignTable.Name] = row
+			rows = append(rows, row)
+		}
+		row.Values[column.ForeignValueColumnName] = column.TestStringValue(2)
+	}
+	return rows
+}
+
+// SqliteType 生成的 service 单元测试中 SQLite 建表语句的字段类型
+func (c *ColumnDef) SqliteType() string {
+	dataType, _ := GetDataType(c.SqlType)
+	sqliteType := "TEXT"
+	switch {
+	case c.IsPk && c.IsIncrement:
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	case IsIntegerObject(dataType) || dataType == "bit":
+		sqliteType = "INTEGER"
+	case IsNumberObject(dataType):
+		sqliteType = "REAL"
+	case IsTimeObject(dataType) || IsDateObject(dataType):
+		sqliteType = "DATETIME"
+	}
+	if c.IsPk {
+		sqliteType += " PRIMARY KEY"
+	}
+	return sqliteType
+}
+
+// TsType 生成的 TypeScript 前端接口中该字段的类型，时间字段以字符串传输
+func (c *ColumnDef) TsType() string {
+	switch {
+	case c.GoType == "bool":
+		return "boolean"
+	case IsIntegerGoType(c.GoType), c.GoType == "float64", c.GoType == "float32":
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// RelatedKeyHtmlField 关联表主键的前端变量名，用于前端构建关联表选项
+func (c *ColumnDef) RelatedKeyHtmlField() string {
+	for _, column := range c.RelatedKeyColumn {
+		return column.HtmlField
+	}
+	return ""
+}
+
+// TestValue 生成的 service 单元测试中该字段第 n 个（1-9）示例值的 Go 表达式，n 越大值越大（bool 类型除外）
+func (c *ColumnDef) TestValue(n int) string {
+	switch c.GoType {
+	case "string":
+		return strconv.Quote(c.testValue(n))
+	case "Time":
+		return "gtime.NewFromStr(" + strconv.Quote(c.testValue(n)) + ")"
+	default:
+		return c.testValue(n)
+	}
+}
+
+// TestStringValue 生成的 service 单元测试中该字段第 n 个示例值的字符串字面量，用于 rpc 模式下以 string 类型传输的时间字段
+func (c *ColumnDef) TestStringValue(n int) string {
+	return strconv.Quote(c.testValue(n))
+}
+
+func (c *ColumnDef) testValue(n int) string {
+	switch c.GoType {
+	case "string":
+		return c.HtmlField + strconv.Itoa(n)
+	case "Time":
+		return "2022-01-0" + strconv.Itoa(n) + " 08:00:00"
+	case "bool":
+		return strconv.FormatBool(n%2 == 1)
+	case "float64":
+		return strconv.Itoa(n) + ".5"
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// TestQueryValue 生成的 service 单元测试中该查询字段的查询条件值（Go 表达式），与 TableDef.TestQueryExpect 对应
+// 测试记录取第 2 个示例值，GT/BETWEEN/NE/NOT IN 以第 1 个示例值为下限或排除值，LT/BETWEEN 以第 3 个示例值为上限
+func (q *QueryColumnDef) TestQueryValue() string {
+	value := func(n int) string {
+		if gstr.TrimLeftStr(q.ReqGoType, "[]") == "string" && q.Base.GoType != "string" {
+			return strconv.Quote(q.Base.testValue(n))
+		}
+		return q.Base.TestValue(n)
+	}
+	switch q.QueryType {
+	case "IS NULL", "IS NOT NULL":
+		return "true"
+	case "NE", "GT":
+		return value(1)
+	case "LT":
+		return value(3)
+	case "IN":
+		return q.ReqGoType + "{" + value(2) + "}"
+	case "NOT IN":
+		return q.ReqGoType + "{" + value(1) + "}"
+	case "BETWEEN":
+		return q.ReqGoType + "{" + value(1) + ", " + value(3) + "}"
+	default:
+		return value(2)
+	}
+}
@@ -16,11 +16,24 @@ type ImportOptions struct {
 	IsRpc               bool
 }
 
+const (
+	CacheBackendGfCache = "gfcache" // 智能缓存使用 gf-cache（Redis），各服务实例共用缓存
+	CacheBackendMemory  = "memory"  // 智能缓存使用进程内缓存，不依赖 Redis
+)
+
+const (
+	FrontendTypeVue  = "vue"  // 前端使用 Vue 2 + Element UI
+	FrontendTypeArco = "arco" // 前端使用 React + Arco Design（TypeScript）
+)
+
 type GenOptions struct {
 	YamlInputPath string
 	GoModuleName  string
 	ServiceOnly   bool
 	SmartCache    bool
+	CacheBackend  string
+	WithTests     bool
+	WithMocks     bool
 	FrontendType  string
 	FrontendPath  string
 }
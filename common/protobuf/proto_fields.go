@@ -0,0 +1,252 @@
+package protobuf
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	messageStartRegex = regexp.MustCompile(`^\s*message\s+(\w+)\s*\{`)
+	fieldRegex        = regexp.MustCompile(`^(\s*(?:repeated\s+|optional\s+)?(?:map\s*<[^>]+>|[\w.]+)\s+)(\w+)(\s*=\s*)(\d+)(.*)$`)
+	reservedRegex     = regexp.MustCompile(`^\s*reserved\s+(.+?)\s*;`)
+)
+
+// messageFields 一个 message 中的字段编号信息
+type messageFields struct {
+	numbers       map[string]int    // 字段名 -> 编号
+	types         map[string]string // 字段名 -> 类型（含 repeated/optional）
+	reserved      map[int]bool      // 已保留的编号
+	reservedNames map[string]bool   // 已保留的字段名
+	maxNumber     int               // 已使用及已保留的最大编号
+}
+
+func newMessageFields() *messageFields {
+	return &messageFields{
+		numbers:       map[string]int{},
+		types:         map[string]string{},
+		reserved:      map[int]bool{},
+		reservedNames: map[string]bool{},
+	}
+}
+
+// parseMessageFields 解析 proto 文件中各顶层 message 的字段编号及 reserved 声明
+func parseMessageFields(content string) map[string]*messageFields {
+	messages := map[string]*messageFields{}
+	var (
+		current *messageFields
+		depth   int
+	)
+	for _, line := range strings.Split(content, "\n") {
+		code := stripLineComment(line)
+		if depth == 0 {
+			if match := messageStartRegex.FindStringSubmatch(code); match != nil {
+				current = newMessageFields()
+				messages[match[1]] = current
+			}
+		} else if depth == 1 && current != nil {
+			if match := fieldRegex.FindStringSubmatch(code); match != nil {
+				number, _ := strconv.Atoi(match[4])
+				current.numbers[match[2]] = number
+				current.types[match[2]] = strings.Join(strings.Fields(match[1]), " ")
+				if number > current.maxNumber {
+					current.maxNumber = number
+				}
+			} else if match := reservedRegex.FindStringSubmatch(code); match != nil {
+				current.addReserved(match[1])
+			}
+		}
+		depth += strings.Count(code, "{") - strings.Count(code, "}")
+		if depth <= 0 {
+			depth = 0
+			current = nil
+		}
+	}
+	return messages
+}
+
+// addReserved 解析 reserved 声明，如 `2, 5 to 7` 或 `"name", "other"`
+func (m *messageFields) addReserved(declaration string) {
+	for _, item := range strings.Split(declaration, ",") {
+		item = strings.TrimSpace(item)
+		if strings.HasPrefix(item, `"`) {
+			m.reservedNames[strings.Trim(item, `"`)] = true
+			continue
+		}
+		from, to := item, item
+		if parts := strings.SplitN(item, " to ", 2); len(parts) == 2 {
+			from, to = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+		start, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for number := start; number <= end; number++ {
+			m.reserved[number] = true
+			if number > m.maxNumber {
+				m.maxNumber = number
+			}
+		}
+	}
+}
+
+func stripLineComment(line string) string {
+	if pos := strings.Index(line, "//"); pos >= 0 {
+		return line[:pos]
+	}
+	return line
+}
+
+// StabilizeFieldNumbers 参照上一次生成的 proto 文件内容，保持已有字段的编号不变：
+// 已有字段沿用原编号；新增字段使用该 message 中从未使用过的新编号；已删除字段的编号及字段名声明为 reserved，不再被复用。
+// previous 中不存在的 message 保持模板生成的编号
+func StabilizeFieldNumbers(content string, previous string) string {
+	if strings.TrimSpace(previous) == "" {
+		return content
+	}
+	oldMessages := parseMessageFields(previous)
+	newMessages := parseMessageFields(content)
+
+	var (
+		builder  strings.Builder
+		current  *messageFields
+		assigned map[string]int
+		depth    int
+	)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		code := stripLineComment(line)
+		if depth == 0 {
+			if match := messageStartRegex.FindStringSubmatch(code); match != nil {
+				current, assigned = nil, nil
+				if old, ok := oldMessages[match[1]]; ok {
+					current = old
+					assigned = assignFieldNumbers(old, newMessages[match[1]])
+				}
+				builder.WriteString(line)
+				if current != nil {
+					writeReserved(&builder, current, newMessages[match[1]])
+				}
+				if i < len(lines)-1 {
+					builder.WriteString("\n")
+				}
+				depth += strings.Count(code, "{") - strings.Count(code, "}")
+				continue
+			}
+		} else if depth == 1 && current != nil {
+			if match := fieldRegex.FindStringSubmatch(line); match != nil {
+				if number, ok := assigned[match[2]]; ok {
+					line = match[1] + match[2] + match[3] + strconv.Itoa(number) + match[5]
+				}
+			} else if reservedRegex.MatchString(code) {
+				// 上一次的 reserved 已由 writeReserved 合并输出
+				continue
+			}
+		}
+		builder.WriteString(line)
+		if i < len(lines)-1 {
+			builder.WriteString("\n")
+		}
+		depth += strings.Count(code, "{") - strings.Count(code, "}")
+		if depth <= 0 {
+			depth = 0
+			current = nil
+		}
+	}
+	return builder.String()
+}
+
+// assignFieldNumbers 为新 message 中的每个字段确定编号，类型改变的字段与新增字段一样使用新编号
+func assignFieldNumbers(old *messageFields, current *messageFields) map[string]int {
+	assigned := map[string]int{}
+	if current == nil {
+		return assigned
+	}
+	var newNames []string
+	for name := range current.numbers {
+		if oldNumber, ok := old.numbers[name]; ok && !typeChanged(old, current, name) {
+			assigned[name] = oldNumber
+		} else {
+			newNames = append(newNames, name)
+		}
+	}
+	// 新增字段按模板中的顺序依次分配编号
+	sort.Slice(newNames, func(i, j int) bool {
+		return current.numbers[newNames[i]] < current.numbers[newNames[j]]
+	})
+	next := old.maxNumber
+	for _, name := range newNames {
+		next++
+		assigned[name] = next
+	}
+	return assigned
+}
+
+// typeChanged 字段类型是否改变，类型改变后沿用旧编号会导致新旧版本之间无法正确解码
+func typeChanged(old *messageFields, current *messageFields, name string) bool {
+	return old.types[name] != current.types[name]
+}
+
+// writeReserved 输出该 message 已保留、本次删除字段及类型改变字段旧编号的 reserved 声明
+func writeReserved(builder *strings.Builder, old *messageFields, current *messageFields) {
+	reserved := map[int]bool{}
+	reservedNames := map[string]bool{}
+	for number := range old.reserved {
+		reserved[number] = true
+	}
+	for name := range old.reservedNames {
+		reservedNames[name] = true
+	}
+	for name, number := range old.numbers {
+		if current == nil || !hasField(current, name) {
+			reserved[number] = true
+			reservedNames[name] = true
+		} else if typeChanged(old, current, name) {
+			// 字段名仍在使用，只保留旧编号
+			reserved[number] = true
+		}
+	}
+	if current != nil {
+		// 重新加回的字段名不再保留（其编号已是新分配的，旧编号仍保留）
+		for name := range current.numbers {
+			delete(reservedNames, name)
+		}
+	}
+	if len(reserved) > 0 {
+		numbers := make([]int, 0, len(reserved))
+		for number := range reserved {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		// 连续的编号合并为 `from to to`
+		var items []string
+		for i := 0; i < len(numbers); {
+			j := i
+			for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+				j++
+			}
+			if j > i {
+				items = append(items, strconv.Itoa(numbers[i])+" to "+strconv.Itoa(numbers[j]))
+			} else {
+				items = append(items, strconv.Itoa(numbers[i]))
+			}
+			i = j + 1
+		}
+		builder.WriteString("\n    reserved " + strings.Join(items, ", ") + ";")
+	}
+	if len(reservedNames) > 0 {
+		names := make([]string, 0, len(reservedNames))
+		for name := range reservedNames {
+			names = append(names, strconv.Quote(name))
+		}
+		sort.Strings(names)
+		builder.WriteString("\n    reserved " + strings.Join(names, ", ") + ";")
+	}
+}
+
+func hasField(message *messageFields, name string) bool {
+	_, ok := message.numbers[name]
+	return ok
+}
@@ -0,0 +1,220 @@
+package protobuf
+
+import "testing"
+
+func TestStabilizeFieldNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		content  string
+		want     string
+	}{
+		{
+			name: "没有上一次的文件",
+			content: `message BookInfo {
+    uint64 id = 1;
+    string name = 2;
+}`,
+			want: `message BookInfo {
+    uint64 id = 1;
+    string name = 2;
+}`,
+		},
+		{
+			name: "字段顺序调整时沿用原编号",
+			previous: `message BookInfo {
+    uint64 id = 1;
+    string name = 2;
+    double price = 3;
+}`,
+			content: `message BookInfo {
+    uint64 id = 1;
+    double price = 2;
+    string name = 3;
+}`,
+			want: `message BookInfo {
+    uint64 id = 1;
+    double price = 3;
+    string name = 2;
+}`,
+		},
+		{
+			name: "新增字段使用新编号",
+			previous: `message BookInfo {
+    uint64 id = 1;
+    string name = 2;
+}`,
+			content: `message BookInfo {
+    uint64 id = 1;
+    string author = 2;
+    string name = 3;
+    repeated string tags = 4;
+}`,
+			want: `message BookInfo {
+    uint64 id = 1;
+    string author = 3;
+    string name = 2;
+    repeated string tags = 4;
+}`,
+		},
+		{
+			name: "删除字段的编号及字段名声明为 reserved",
+			previous: `message BookInfo {
+    uint64 id = 1;
+    string name = 2;
+    double price = 3;
+    string author = 4;
+}`,
+			content: `message BookInfo {
+    uint64 id = 1;
+    string author = 2;
+}`,
+			want: `message BookInfo {
+    reserved 2 to 3;
+    reserved "name", "price";
+    uint64 id = 1;
+    string author = 4;
+}`,
+		},
+		{
+			name: "重新加回的字段使用新编号，旧编号仍保留",
+			previous: `message BookInfo {
+    reserved 2;
+    reserved "name";
+    uint64 id = 1;
+    string author = 3;
+}`,
+			content: `message BookInfo {
+    uint64 id = 1;
+    string name = 2;
+    string author = 3;
+}`,
+			want: `message BookInfo {
+    reserved 2;
+    uint64 id = 1;
+    string name = 4;
+    string author = 3;
+}`,
+		},
+		{
+			name: "类型改变的字段使用新编号并保留旧编号",
+			previous: `message BookInfo {
+    uint64 id = 1;
+    int32 status = 2;
+    string name = 3;
+}`,
+			content: `message BookInfo {
+    uint64 id = 1;
+    string status = 2;
+    repeated string name = 3;
+}`,
+			want: `message BookInfo {
+    reserved 2 to 3;
+    uint64 id = 1;
+    string status = 4;
+    repeated string name = 5;
+}`,
+		},
+		{
+			name: "已有的 reserved 区间参与编号分配",
+			previous: `message BookInfo {
+    reserved 2 to 4, 6;
+    reserved "name";
+    uint64 id = 1;
+    string author = 5;
+}`,
+			content: `message BookInfo {
+    uint64 id = 1;
+    string author = 2;
+    string publisher = 3;
+}`,
+			want: `message BookInfo {
+    reserved 2 to 4, 6;
+    reserved "name";
+    uint64 id = 1;
+    string author = 5;
+    string publisher = 7;
+}`,
+		},
+		{
+			name: "嵌套 message 及上一次不存在的 message 保持模板编号",
+			previous: `message BookListRes {
+    repeated BookInfo list = 1;
+    int64 total = 2;
+}`,
+			content: `message BookListRes {
+    int64 total = 1;
+    repeated BookInfo list = 2;
+    message Extra {
+        string note = 1;
+    }
+}
+
+message BookSortField {
+    string field = 1;
+    string direction = 2;
+}`,
+			want: `message BookListRes {
+    int64 total = 2;
+    repeated BookInfo list = 1;
+    message Extra {
+        string note = 1;
+    }
+}
+
+message BookSortField {
+    string field = 1;
+    string direction = 2;
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StabilizeFieldNumbers(tt.content, tt.previous)
+			if got != tt.want {
+				t.Errorf("StabilizeFieldNumbers() =\n%s\nwant\n%s", got, tt.want)
+			}
+			// 以本次结果作为上一次的文件再次生成，结果不变
+			if again := StabilizeFieldNumbers(tt.content, got); tt.previous != "" && again != got {
+				t.Errorf("再次生成的结果不一致：\n%s\nwant\n%s", again, got)
+			}
+		})
+	}
+}
+
+func TestParseMessageFields(t *testing.T) {
+	messages := parseMessageFields(`syntax = "proto3";
+
+// 图书
+message BookInfo {
+    reserved 3, 5 to 6;
+    reserved "price";
+    uint64 id = 1; // 主键
+    map<string, string> attrs = 2;
+    optional string name = 4;
+}`)
+	book, ok := messages["BookInfo"]
+	if !ok {
+		t.Fatalf("未解析到 BookInfo")
+	}
+	wantNumbers := map[string]int{"id": 1, "attrs": 2, "name": 4}
+	for name, number := range wantNumbers {
+		if book.numbers[name] != number {
+			t.Errorf("%s 的编号为 %d，应为 %d", name, book.numbers[name], number)
+		}
+	}
+	if book.types["name"] != "optional string" {
+		t.Errorf("name 的类型为 %q，应为 %q", book.types["name"], "optional string")
+	}
+	for _, number := range []int{3, 5, 6} {
+		if !book.reserved[number] {
+			t.Errorf("编号 %d 应为 reserved", number)
+		}
+	}
+	if !book.reservedNames["price"] {
+		t.Errorf("字段名 price 应为 reserved")
+	}
+	if book.maxNumber != 6 {
+		t.Errorf("maxNumber 为 %d，应为 6", book.maxNumber)
+	}
+}
@@ -2,80 +2,321 @@ package protobuf
 
 import (
 	"bytes"
-	"github.com/gogf/gf/v2/text/gstr"
-	"io/ioutil"
+	"context"
+	"embed"
+	"io"
 	"os/exec"
 	"path"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/text/gstr"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
-func IsProtocVersionOK() (bool, error) {
-	var (
-		err     error
-		bytes   []byte
-		version string
-	)
-	cmd := exec.Command("protoc", "--version")
-	bytes, err = cmd.Output()
-	if err != nil { //获取输出对象，可以从该对象中读取输出结果
-		return false, err
-	}
-	version = string(bytes)
-	return gstr.ContainsI(version, "protoc 3."), nil
-}
+// includeFS 内置的 google/api/annotations.proto 等公共 proto 文件，用于 google.api.http 注解
+//
+//go:embed include
+var includeFS embed.FS
 
-func IsProtocTripleVersionOK() (bool, error) {
-	var (
-		err     error
-		bytes   []byte
-		version string
-	)
-	cmd := exec.Command("protoc-gen-go-triple", "--version")
-	bytes, err = cmd.Output()
-	if err != nil { //获取输出对象，可以从该对象中读取输出结果
-		return false, err
-	}
-	version = string(bytes)
-	return gstr.ContainsI(version, "protoc-gen-go-triple 1."), nil
+// 代码生成插件，在生成项目的目录下通过 go run 运行，版本及校验和取自项目的 go.mod/go.sum，无需预先安装。
+// 项目尚未依赖插件模块时，先以 pluginVersions 中的版本 go get 加入 go.mod，并在 PluginToolsFile 中引用，避免被 go mod tidy 移除
+const (
+	ProtocGenGo       = "google.golang.org/protobuf/cmd/protoc-gen-go"
+	ProtocGenGoGrpc   = "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
+	ProtocGenGoTriple = "github.com/dubbogo/tools/cmd/protoc-gen-go-triple"
+)
+
+// PluginToolsFile 引用 protoc 插件的 tools.go，相对于 Go module 根目录
+const PluginToolsFile = "library/tools/tools.go"
+
+// pluginVersions 项目尚未依赖插件模块时加入 go.mod 的版本
+var pluginVersions = map[string]string{
+	ProtocGenGo:       "v1.30.0",
+	ProtocGenGoGrpc:   "v1.2.0",
+	ProtocGenGoTriple: "v1.0.8",
 }
 
-func CallProtoc(curDir string, packageName string, goFileName string, seperatedPackage bool) error {
+// CompileProto 在进程内编译 {packageName}/proto/{goFileName}.proto（不依赖 protoc），生成的代码写入同级的 model 目录：
+// {goFileName}.pb.go 由 ProtocGenGo 生成；{goFileName}.pb.json.go 为各 message 的 MarshalJSON；
+// {goFileName}_grpc.pb.go 或 {goFileName}_triple.pb.go 由 ProtocGenGoGrpc 或 ProtocGenGoTriple 生成。
+// packageName 为相对于 Go module 根目录的路径
+func CompileProto(ctx context.Context, curDir string, packageName string, goFileName string, separatePackage bool, isGrpc bool) error {
 	var (
 		protoPath string
-		pbGoPath  string
-		err       error
+		modelPath string
 	)
-	packageName = gstr.SubStrFromEx(packageName, "devops.gitlab.zfkunyu.com/cartsee-go/cartx-etl/")
-	if seperatedPackage {
+	if separatePackage {
 		protoPath = path.Join(curDir, packageName, goFileName, "proto")
+		modelPath = path.Join(curDir, packageName, goFileName, "model")
 	} else {
 		protoPath = path.Join(curDir, packageName, "proto")
+		modelPath = path.Join(curDir, packageName, "model")
 	}
-	cmd := exec.Command("protoc", "-I"+protoPath, "--go_out=.", "--go-triple_out=.", goFileName+".proto")
-	cmd.Dir = curDir
-	_, err = cmd.Output()
+	req, err := compile(ctx, protoPath, goFileName+".proto")
 	if err != nil {
 		return err
 	}
-	if seperatedPackage {
-		pbGoPath = path.Join(curDir, packageName, goFileName, "model", goFileName+".pb.go")
-	} else {
-		pbGoPath = path.Join(curDir, packageName, "model", goFileName+".pb.go")
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		return gerror.Wrap(err, "生成 pb 代码失败")
 	}
-	err = ReplaceString(pbGoPath, ",omitempty", "")
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	for _, f := range gen.Files {
+		if f.Generate {
+			generateJsonFile(gen, f)
+		}
+	}
+	err = writeResponse(gen.Response(), modelPath)
 	if err != nil {
 		return err
 	}
+	rpcPlugin := ProtocGenGoTriple
+	if isGrpc {
+		rpcPlugin = ProtocGenGoGrpc
+	}
+	plugins := []string{ProtocGenGo, rpcPlugin}
+	if err = requirePlugins(ctx, curDir, plugins); err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		res, err := runPlugin(ctx, curDir, plugin, req)
+		if err != nil {
+			return err
+		}
+		if err = writeResponse(res, modelPath); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func ReplaceString(fileName string, original, replacement string) error {
-	input, err := ioutil.ReadFile(fileName)
+// compile 解析 proto 文件及其依赖，返回 protoc 插件的请求参数
+func compile(ctx context.Context, protoPath string, protoFile string) (*pluginpb.CodeGeneratorRequest, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{ImportPaths: []string{protoPath}},
+			&protocompile.SourceResolver{Accessor: func(name string) (io.ReadCloser, error) {
+				return includeFS.Open(path.Join("include", name))
+			}},
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(ctx, protoFile)
 	if err != nil {
-		return err
+		return nil, gerror.Wrapf(err, "编译 %s 失败", path.Join(protoPath, protoFile))
 	}
-	output := bytes.Replace(input, []byte(original), []byte(replacement), -1)
-	if err = ioutil.WriteFile(fileName, output, 0644); err != nil {
-		return err
+	var (
+		protoFiles []*descriptorpb.FileDescriptorProto
+		seen       = map[string]bool{}
+		add        func(fd protoreflect.FileDescriptor)
+	)
+	// 依赖的文件须排在前面
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		protoFiles = append(protoFiles, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, f := range files {
+		add(f)
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{protoFile},
+		Parameter:      proto.String("paths=source_relative"),
+		ProtoFile:      protoFiles,
+	}, nil
+}
+
+// requirePlugins 确保项目的 go.mod 中依赖了插件所在的模块，并在 PluginToolsFile 中引用全部已使用的插件
+func requirePlugins(ctx context.Context, curDir string, plugins []string) error {
+	toolsPath := path.Join(curDir, PluginToolsFile)
+	content := gfile.GetContents(toolsPath)
+	missing := false
+	for _, plugin := range plugins {
+		if !strings.Contains(content, strconv.Quote(plugin)) {
+			missing = true
+		}
+		// 已依赖插件模块时 go list 能够解析插件包，此时沿用项目中的版本
+		if runGo(ctx, curDir, "list", plugin) == nil {
+			continue
+		}
+		if err := runGo(ctx, curDir, "get", plugin+"@"+pluginVersions[plugin]); err != nil {
+			return err
+		}
+	}
+	if !missing {
+		return nil
+	}
+	var imports []string
+	for _, plugin := range []string{ProtocGenGo, ProtocGenGoGrpc, ProtocGenGoTriple} {
+		if strings.Contains(content, strconv.Quote(plugin)) || gstr.InArray(plugins, plugin) {
+			imports = append(imports, "\t_ "+strconv.Quote(plugin))
+		}
+	}
+	return gfile.PutContents(toolsPath, `//go:build tools
+// +build tools
+
+// Code generated by gf-codegen. DO NOT EDIT.
+// 引用 gf-codegen 使用的 protoc 插件，使其模块版本保留在 go.mod 中
+
+package tools
+
+import (
+`+strings.Join(imports, "\n")+`
+)
+`)
+}
+
+// runGo 在项目目录下执行 go 命令
+func runGo(ctx context.Context, curDir string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = curDir
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return gerror.Wrapf(err, "执行 go %s 失败：%s", strings.Join(args, " "), stderr.String())
 	}
 	return nil
 }
+
+// runPlugin 以 go run 运行项目 go.mod 中锁定版本的 protoc 插件
+func runPlugin(ctx context.Context, curDir string, plugin string, req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	input, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", "run", plugin)
+	cmd.Dir = curDir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, gerror.Wrapf(err, "运行 %s 失败：%s", plugin, stderr.String())
+	}
+	res := &pluginpb.CodeGeneratorResponse{}
+	if err = proto.Unmarshal(stdout.Bytes(), res); err != nil {
+		return nil, gerror.Wrapf(err, "解析 %s 的输出失败", plugin)
+	}
+	return res, nil
+}
+
+// writeResponse 将插件生成的文件写入 model 目录
+func writeResponse(res *pluginpb.CodeGeneratorResponse, modelPath string) error {
+	if res.Error != nil {
+		return gerror.New(res.GetError())
+	}
+	for _, f := range res.File {
+		err := gfile.PutContents(path.Join(modelPath, path.Base(f.GetName())), f.GetContent())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateJsonFile 为各 message 生成 MarshalJSON：
+// protoc-gen-go 生成的 json tag 均带 omitempty，直接用 encoding/json 输出时零值字段会被省略，
+// 这里按字段定义顺序输出全部字段（字段名与 proto 中定义的一致），数值保持 JSON 数字（protojson 会将 64 位整数输出为字符串），
+// repeated 及 map 字段为空时输出 [] 及 {}
+func generateJsonFile(gen *protogen.Plugin, file *protogen.File) {
+	if len(file.Messages) == 0 {
+		return
+	}
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+".pb.json.go", file.GoImportPath)
+	g.P("// Code generated by gf-codegen. DO NOT EDIT.")
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	jsonMarshal := g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       "Marshal",
+		GoImportPath: "encoding/json",
+	})
+	var genMessage func(message *protogen.Message)
+	genMessage = func(message *protogen.Message) {
+		if message.Desc.IsMapEntry() {
+			return
+		}
+		g.P("// MarshalJSON 输出 ", message.GoIdent.GoName, " 的全部字段，包括零值字段")
+		g.P("func (x *", message.GoIdent.GoName, ") MarshalJSON() ([]byte, error) {")
+		g.P("if x == nil {")
+		g.P(`return []byte("null"), nil`)
+		g.P("}")
+		g.P("v := struct {")
+		for _, field := range message.Fields {
+			g.P(field.GoName, " ", fieldGoType(g, field), " `json:\"", field.Desc.Name(), "\"`")
+		}
+		g.P("}{")
+		for _, field := range message.Fields {
+			g.P(field.GoName, ": x.Get", field.GoName, "(),")
+		}
+		g.P("}")
+		for _, field := range message.Fields {
+			if field.Desc.IsList() || field.Desc.IsMap() {
+				g.P("if v.", field.GoName, " == nil {")
+				g.P("v.", field.GoName, " = ", fieldGoType(g, field), "{}")
+				g.P("}")
+			}
+		}
+		g.P("return ", jsonMarshal, "(v)")
+		g.P("}")
+		g.P()
+		for _, nested := range message.Messages {
+			genMessage(nested)
+		}
+	}
+	for _, message := range file.Messages {
+		genMessage(message)
+	}
+}
+
+// fieldGoType 字段在 protoc-gen-go 生成的结构体中的 Go 类型
+func fieldGoType(g *protogen.GeneratedFile, field *protogen.Field) string {
+	if field.Desc.IsMap() {
+		return "map[" + fieldGoType(g, field.Message.Fields[0]) + "]" + fieldGoType(g, field.Message.Fields[1])
+	}
+	var goType string
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		goType = "bool"
+	case protoreflect.EnumKind:
+		goType = g.QualifiedGoIdent(field.Enum.GoIdent)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		goType = "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		goType = "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		goType = "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		goType = "uint64"
+	case protoreflect.FloatKind:
+		goType = "float32"
+	case protoreflect.DoubleKind:
+		goType = "float64"
+	case protoreflect.StringKind:
+		goType = "string"
+	case protoreflect.BytesKind:
+		goType = "[]byte"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		goType = "*" + g.QualifiedGoIdent(field.Message.GoIdent)
+	}
+	if field.Desc.IsList() {
+		return "[]" + goType
+	}
+	return goType
+}
@@ -29,6 +29,9 @@ var (
 	ColumnNameNotList   = []string{"updated_by", "updated_at", "deleted_at"}
 	ColumnNameNotDetail = []string{"updated_by", "updated_at", "deleted_at"}
 	ColumnNameNotQuery  = []string{"updated_by", "updated_at", "deleted_at", "remark"}
+	ColumnNameVersion   = []string{"version", "revision"}
+	GoTypeInteger       = []string{"int", "int32", "int64", "uint", "uint32", "uint64"}
+	QueryTypes          = []string{"EQ", "NE", "GT", "GTE", "LT", "LTE", "LIKE", "PREFIX", "SUFFIX", "BETWEEN", "IN", "NOT IN", "IS NULL", "IS NOT NULL"}
 )
 
 // IsExistInArray 判断 value 是否存在在切片array中
@@ -72,6 +75,16 @@ func IsNumberObject(dataType string) bool {
 	return IsExistInArray(dataType, ColumnTypeNumber)
 }
 
+// IsIntegerObject 是否整数类型
+func IsIntegerObject(dataType string) bool {
+	return IsNumberObject(dataType) && !IsExistInArray(dataType, []string{"float", "double", "decimal", "numeric", "bit"})
+}
+
+// IsIntegerGoType 是否Go整数类型
+func IsIntegerGoType(goType string) bool {
+	return IsExistInArray(goType, GoTypeInteger)
+}
+
 func GetGoModuleName() (string, error) {
 	curDir, err := os.Getwd()
 	if err != nil {
@@ -105,7 +118,7 @@ func getBusinessName(tableName string, tablePrefix []string) string {
 	return removeTablePrefix(tableName, tablePrefix)
 }
 
-//删除表前缀
+// 删除表前缀
 func removeTablePrefix(tableName string, tablePrefix []string) string {
 	if !g.IsEmpty(tablePrefix) {
 		for _, str := range tablePrefix {
@@ -131,6 +144,7 @@ func LoadTableDefYaml(ctx context.Context, tableName string, yamlInputPath strin
 	if err != nil {
 		return nil, err
 	}
+	table.PkColumns = make(map[string]*ColumnDef)
 	table.SetVariableNames(goModuleName)
 	table.ColumnMap = def.Columns
 	table.VirtualColumnMap = def.VirtualColumns
@@ -152,8 +166,15 @@ func LoadTableDefYaml(ctx context.Context, tableName string, yamlInputPath strin
 	if hasCreatedBy {
 		table.CreatedByColumn = createBy
 	}
-	_, hasUpdateBy := table.ColumnMap["updated_by"]
+	updatedAt, hasUpdatedAt := table.ColumnMap["updated_at"]
+	if hasUpdatedAt {
+		table.UpdatedAtColumn = updatedAt
+	}
+	updateBy, hasUpdateBy := table.ColumnMap["updated_by"]
 	table.HasUpdatedBy = hasUpdateBy
+	if hasUpdateBy {
+		table.UpdatedByColumn = updateBy
+	}
 	table.RefColumns = gmap.NewListMap()
 	table.VirtualQueryRelated = make(map[string]*TableDef)
 
@@ -161,10 +182,23 @@ func LoadTableDefYaml(ctx context.Context, tableName string, yamlInputPath strin
 	if err != nil {
 		return nil, err
 	}
+	err = table.ProcessHistoryTable(goModuleName)
+	if err != nil {
+		return nil, err
+	}
 	cache[tableName] = table
 	return table, nil
 }
 
+// TableDefYamlExists 表是否有对应的 yaml 定义文件
+func TableDefYamlExists(tableName string, yamlInputPath string) bool {
+	curDir, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	return gfile.Exists(path.Join(curDir, yamlInputPath, tableName+".yaml"))
+}
+
 func loadCodeDefYaml(ctx context.Context, tableName string, yamlInputPath string) (*CodeGenDef, error) {
 	curDir, err := os.Getwd()
 	if err != nil {
@@ -202,7 +236,8 @@ func TemplateEngine() *gview.View {
 		"VueTag": func(t string) string {
 			return t
 		},
-		"IsEmpty": g.IsEmpty, //是否为空
+		"IsIntegerGoType": IsIntegerGoType, //是否为整数类型
+		"IsEmpty":         g.IsEmpty,       //是否为空
 		"IsNotEmpty": func(value interface{}) bool {
 			return !g.IsEmpty(value)
 		},
@@ -126,8 +126,20 @@ func (s *dbTableImporter) fillTableDef(ctx context.Context, table *common.TableD
 			table.HasCreatedBy = true
 			table.CreatedByColumn = column
 		}
+		if columnName == "updated_at" {
+			table.UpdatedAtColumn = column
+		}
 		if columnName == "updated_by" {
 			table.HasUpdatedBy = true
+			table.UpdatedByColumn = column
+		}
+		isVersion := false
+		dataType, _ := common.GetDataType(column.SqlType)
+		if table.VersionColumnName == "" && common.IsExistInArray(columnName, common.ColumnNameVersion) &&
+			common.IsIntegerObject(dataType) {
+			// 整数类型的 version/revision 字段自动作为乐观锁版本字段
+			table.VersionColumnName = columnName
+			isVersion = true
 		}
 		listColumnDefault := s.getListColumnDefault(column)
 		addColumnDefault := s.getAddColumnDefault(column)
@@ -135,7 +147,7 @@ func (s *dbTableImporter) fillTableDef(ctx context.Context, table *common.TableD
 		queryColumnDefault := s.getQueryColumnDefault(column)
 		detailColumnDefault := s.getDetailColumnDefault(column)
 
-		if !column.IsPk || !column.IsIncrement {
+		if (!column.IsPk || !column.IsIncrement) && !isVersion && !common.IsExistInArray(columnName, common.ColumnNameNotEdit) {
 			table.AddColumns = append(table.AddColumns, addColumnDefault)
 			if column.IsPk {
 				editColumnDefault.IsDisabled = true
@@ -147,9 +159,42 @@ func (s *dbTableImporter) fillTableDef(ctx context.Context, table *common.TableD
 		table.DetailColumns = append(table.DetailColumns, detailColumnDefault)
 		table.Columns = append(table.Columns, column)
 	}
+	// 存在 FULLTEXT 索引时，缺省生成基于该索引字段的全文关键字搜索
+	fulltextColumns, err := s.selectFulltextColumnsByName(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if len(fulltextColumns) > 0 {
+		table.KeywordSearch = &common.KeywordSearchDef{
+			Columns: fulltextColumns,
+			Mode:    "fulltext",
+		}
+	}
 	return nil
 }
 
+// selectFulltextColumnsByName 查询表的第一个 FULLTEXT 索引包含的字段
+func (s *dbTableImporter) selectFulltextColumnsByName(ctx context.Context, tableName string) ([]string, error) {
+	db := g.DB(gdb.DefaultGroupName)
+	sql := " select index_name, column_name" +
+		"      from information_schema.statistics" +
+		"     where table_schema = (select database()) " +
+		"       and index_type = 'FULLTEXT' "
+	sql += " and " + gdb.FormatSqlWithArgs(" table_name=? ", []interface{}{tableName}) + " order by index_name ASC, seq_in_index ASC "
+	result, err := db.GetAll(ctx, sql)
+	if err != nil {
+		return nil, gerror.New("查询全文索引信息失败")
+	}
+	var columns []string
+	for _, record := range result {
+		if record["index_name"].String() != result[0]["index_name"].String() {
+			break
+		}
+		columns = append(columns, record["column_name"].String())
+	}
+	return columns, nil
+}
+
 // selectDbTableColumnsByName 根据表名称查询列信息
 func (s *dbTableImporter) selectDbTableColumnsByName(ctx context.Context, tableName string) ([]*common.ColumnDef, error) {
 	db := g.DB(gdb.DefaultGroupName)
@@ -272,6 +317,7 @@ func (s *dbTableImporter) getListColumnDefault(base *common.ColumnDef) *common.L
 		Sort:              base.Sort,
 		IsOverflowTooltip: true,
 		MinWidth:          100,
+		IsSortable:        base.IsPk || base.Name == "created_at" || base.Name == "updated_at",
 	}
 }
 